@@ -0,0 +1,163 @@
+package dbmap
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ColumnSpec describes one column of a descriptor built at runtime with
+// DescribeColumns(). Name is the name of the column in the database table and
+// Kind is the kind of Go value it is retrieved as.
+type ColumnSpec struct {
+	Name string
+	Kind reflect.Kind
+}
+
+// glKindTpMap associates the kinds supported in a ColumnSpec with the type of
+// the value retrieved for them.
+var glKindTpMap = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Slice:   reflect.TypeOf([]byte{}),
+}
+
+// DescribeColumns generates a read-only descriptor for the table tableName
+// from the runtime column specification cols rather than from a tagged
+// structure. This is useful for reports whose shape is not known at compile
+// time. Records are retrieved with Query(), QueryRow() and Next() into either
+// a map[string]interface{} (or a pointer to one), keyed by column name, or a
+// pointer to a []interface{} holding one value per column. A column of kind
+// reflect.Slice is retrieved as []byte; a column of any other kind that is not
+// a boolean, number or string is retrieved as supplied by the driver. A NULL
+// value is retrieved as nil. Commands that modify the table, such as Insert()
+// and Create(), fail with an error.
+func DescribeColumns(tableName string, cols []ColumnSpec) (dsc DscType) {
+	dsc.tblStr = tableName
+	dsc.colList = make([]ColumnSpec, len(cols))
+	copy(dsc.colList, cols)
+	for _, col := range cols {
+		dsc.sel.nameList.append(col.Name)
+	}
+	dsc.dialect = SQLiteDialect{}
+	dsc.assemble()
+	return
+}
+
+// writable returns an error if the receiver was generated by
+// DescribeColumns() and consequently cannot be used to modify its table.
+func (dsc DscType) writable() (err error) {
+	if dsc.colList != nil {
+		err = fmt.Errorf("descriptor of table %s is read-only", dsc.tblStr)
+	}
+	return
+}
+
+// columnArg returns the scan targets for a record of a descriptor generated
+// by DescribeColumns(). See SelectArg().
+func (dsc DscType) columnArg(recPtr interface{}) (argList []interface{}, err error) {
+	argList = make([]interface{}, len(dsc.colList))
+	switch rec := recPtr.(type) {
+	case map[string]interface{}:
+		if rec == nil {
+			return nil, errors.New("passed-in map must not be nil")
+		}
+		for j, col := range dsc.colList {
+			argList[j] = columnScanType{kind: col.Kind, mp: rec, nameStr: col.Name}
+		}
+	case *map[string]interface{}:
+		if *rec == nil {
+			*rec = make(map[string]interface{})
+		}
+		for j, col := range dsc.colList {
+			argList[j] = columnScanType{kind: col.Kind, mp: *rec, nameStr: col.Name}
+		}
+	case *[]interface{}:
+		if len(*rec) != len(dsc.colList) {
+			*rec = make([]interface{}, len(dsc.colList))
+		}
+		for j, col := range dsc.colList {
+			argList[j] = columnScanType{kind: col.Kind, ptr: &(*rec)[j]}
+		}
+	default:
+		argList = nil
+		err = fmt.Errorf("passed-in value for select from %s must be a map[string]interface{} "+
+			"or a pointer to a map[string]interface{} or []interface{}", dsc.tblStr)
+	}
+	return
+}
+
+// columnScanType is a scan target for one column of a descriptor generated by
+// DescribeColumns(). The retrieved value is stored either in the map mp with
+// the key nameStr or in the variable pointed to by ptr.
+type columnScanType struct {
+	kind    reflect.Kind
+	mp      map[string]interface{}
+	nameStr string
+	ptr     *interface{}
+}
+
+// Scan implements the sql.Scanner interface.
+func (cs columnScanType) Scan(src interface{}) (err error) {
+	var val interface{}
+	if src != nil {
+		tp, ok := glKindTpMap[cs.kind]
+		if !ok {
+			tp = reflect.TypeOf(src)
+		}
+		switch tp.Kind() {
+		case reflect.Bool:
+			var n sql.NullBool
+			err = n.Scan(src)
+			val = n.Bool
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var n sql.NullInt64
+			err = n.Scan(src)
+			val = reflect.ValueOf(n.Int64).Convert(tp).Interface()
+		case reflect.Float32, reflect.Float64:
+			var n sql.NullFloat64
+			err = n.Scan(src)
+			val = reflect.ValueOf(n.Float64).Convert(tp).Interface()
+		case reflect.String:
+			var n sql.NullString
+			err = n.Scan(src)
+			val = n.String
+		default:
+			switch v := src.(type) {
+			case []byte:
+				// The driver may reuse its buffer
+				val = append([]byte{}, v...)
+			case string:
+				if cs.kind == reflect.Slice {
+					val = []byte(v)
+				} else {
+					val = v
+				}
+			default:
+				val = v
+			}
+		}
+	}
+	if err == nil {
+		if cs.ptr != nil {
+			*cs.ptr = val
+		} else {
+			cs.mp[cs.nameStr] = val
+		}
+	}
+	return
+}
@@ -1,58 +1,117 @@
 package dbmap
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type tmType map[string]string
 
 var typeMap = tmType{
-	"[]uint8": "blob",
-	"bool":    "integer",
-	"byte":    "integer",
-	"float":   "real",
-	"float32": "real",
-	"float64": "real",
-	"int":     "integer",
-	"int16":   "integer",
-	"int32":   "integer",
-	"int64":   "integer",
-	"int8":    "integer",
-	"rune":    "integer",
-	"string":  "text",
-	"uint":    "integer",
-	"uint16":  "integer",
-	"uint32":  "integer",
-	"uint64":  "integer",
-	"uint8":   "integer",
+	"*time.Time":      "datetime",
+	"[]uint8":         "blob",
+	"bool":            "integer",
+	"byte":            "integer",
+	"float":           "real",
+	"float32":         "real",
+	"float64":         "real",
+	"int":             "integer",
+	"int16":           "integer",
+	"int32":           "integer",
+	"int64":           "integer",
+	"int8":            "integer",
+	"rune":            "integer",
+	"sql.NullBool":    "integer",
+	"sql.NullByte":    "integer",
+	"sql.NullFloat64": "real",
+	"sql.NullInt16":   "integer",
+	"sql.NullInt32":   "integer",
+	"sql.NullInt64":   "integer",
+	"sql.NullString":  "text",
+	"sql.NullTime":    "datetime",
+	"string":          "text",
+	"time.Time":       "datetime",
+	"uint":            "integer",
+	"uint16":          "integer",
+	"uint32":          "integer",
+	"uint64":          "integer",
+	"uint8":           "integer",
+}
+
+// glStrictTypeMap translates column types that SQLite does not permit in
+// strict tables. The types integer, real, text and blob are permitted.
+var glStrictTypeMap = tmType{
+	"datetime": "text",
 }
 
 type idxType struct {
-	nameStr string
-	fldStr  string
+	// Position of this key segment within the index, from the numeric
+	// suffix of the tag, for example 2 in "loc2"
+	seq    int
+	fldStr string
+	// Collation of this key segment, for example "NOCASE"; empty for the
+	// column's own collation
+	collateStr string
 }
 
 type idxListType []idxType
 
 type idxMapType map[string]idxListType
 
+// names returns the index names of the map in sorted order.
+func (m idxMapType) names() (list []string) {
+	for k := range m {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return
+}
+
+// fkType describes a foreign key constraint declared with a "db_fk" tag
+type fkType struct {
+	// Column of the tagged field
+	nameStr string
+	// Referenced table and column
+	tblStr string
+	colStr string
+	// Referencing records are deleted with the referenced record
+	cascade bool
+}
+
 func (list idxListType) Len() int {
 	return len(list)
 }
 
 func (list idxListType) Less(i, j int) bool {
-	return list[i].nameStr < list[j].nameStr
+	return list[i].seq < list[j].seq
 }
 
 func (list idxListType) Swap(i, j int) {
 	list[i], list[j] = list[j], list[i]
 }
 
+// duplicate returns a sequence number that occurs more than once in the
+// sorted list, and true, or false if each occurs once.
+func (list idxListType) duplicate() (seq int, found bool) {
+	for j := 1; j < len(list) && !found; j++ {
+		seq = list[j].seq
+		found = seq == list[j-1].seq
+	}
+	return
+}
+
 type strListType []string
 
 func (list *strListType) append(str string) {
@@ -88,19 +147,80 @@ func prePad(str string) string {
 type DscType struct {
 	// Name of database table
 	tblStr string
+	// Name of database table as it appears in SQL; quoted if reserved
+	tblIdentStr string
+	// SQL dialect used to generate commands
+	dialect Dialect
 	// Primary key is present in table
 	idPresent bool
 	// Descriptor for primary key if present
 	idSf reflect.StructField
+	// Column of primary key named by the "db_primary" tag; empty for the
+	// dialect's default, for example rowid
+	idNameStr string
+	// Columns tagged with both "db" and "db_primary"; a composite key if
+	// more than one
+	key struct {
+		// {"tenant", "code"}
+		nameList strListType
+		sfList   sfListType
+	}
 	// Record interface
 	recTp reflect.Type
+	// Runtime column specification; non-nil only for read-only descriptors
+	// generated by DescribeColumns()
+	colList []ColumnSpec
 	// {"num":sfNum, "name":sfName, ...}
 	nameMap map[string]reflect.StructField
-	create  struct {
+	// {"status":{1:true, 2:true}, ...}; valid values of enumerated fields
+	enumMap map[string]map[interface{}]bool
+	// {"email":true, "name":false, ...}; string fields whose values are
+	// trimmed on write, true if also converted to lower case
+	trimMap map[string]bool
+	// {"created":false, "updated":true, ...}; timestamp fields set
+	// automatically on insertion, true if also set on update
+	autoMap map[string]bool
+	// {"parent":int64(-1), ...}; values stored in place of the zero value of
+	// fields tagged with "db_zero"
+	zeroMap map[string]interface{}
+	// {"attrs":true, ...}; fields tagged with "db_json", stored as JSON text
+	jsonMap map[string]bool
+	// Field tagged with "db_softdelete", if any
+	soft struct {
+		// "deleted_at"; empty if records are removed when deleted
+		nameStr string
+		sf      reflect.StructField
+	}
+	create struct {
 		// "num int32, name string, ..."
 		nameTypeStr string
+		// {"num int32", "name text NOT NULL", ...}; one for each inserted
+		// field
+		colDefList strListType
+		// {"integer", "text", ...}; one for each inserted field
+		typeList strListType
+		// {"", "varchar(255)", ...}; one for each inserted field, the type
+		// declared in place of the one in typeList if not empty
+		overrideList strListType
+		// Table enforces column types (SQLite STRICT)
+		strict bool
+		// {"", "NOCASE", ...}; one for each inserted field
+		collateList strListType
+		// {false, true, ...}; one for each inserted field, true if NOT NULL
+		notNullList []bool
+		// {"", "'active'", "CURRENT_TIMESTAMP", ...}; one for each inserted
+		// field
+		defaultList strListType
+		// {"", "age >= 0", ...}; one for each inserted field, the expression
+		// of the field's CHECK constraint if not empty
+		checkList strListType
 		// {{"fooID", "rowid"}, {"fooName", "Name"}, {"fooNum", "Num"}, ...}
 		idxMap idxMapType
+		// Like idxMap, for indexes that do not permit duplicate keys
+		uniqueMap idxMapType
+		// {{"author_id", "author", "code", true}, ...}; foreign key
+		// constraints declared with "db_fk" tags
+		fkList []fkType
 	}
 	insert struct {
 		// "num, name, ..."
@@ -114,6 +234,8 @@ type DscType struct {
 	sel struct {
 		// "rowid, num, name, ..."
 		nameStr string
+		// {"", "num", "name", ...}; empty for primary key
+		nameList strListType
 		// Includes ID if present in structure
 		sfList sfListType
 		// {"int64", "bigint", "string", ...}
@@ -121,22 +243,79 @@ type DscType struct {
 	}
 }
 
-var glIdxRe = regexp.MustCompile("^\\s*(\\D{1,})(\\d{1,})\\s*$")
+// glIdxRe splits a key segment such as "loc5 NOCASE" into the index name,
+// the sequence (verified to be an integer separately) and the collation
+var glIdxRe = regexp.MustCompile("^\\s*([^\\s\\d]+)(\\S*)(?:\\s+(\\w+))?\\s*$")
+
+var glScannerTp = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+var glValuerTp = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// valuerType returns the column type of a field of type tp that is stored and
+// retrieved by way of its own driver.Valuer and sql.Scanner methods. The type
+// is chosen according to the value that the zero value of tp stores, for
+// example text for a string; it is blob if that value is nil or cannot be
+// determined. ok is false if tp does not implement driver.Valuer or if *tp
+// does not implement sql.Scanner.
+func valuerType(tp reflect.Type) (typeStr string, ok bool) {
+	if tp.Implements(glValuerTp) && reflect.PtrTo(tp).Implements(glScannerTp) {
+		typeStr, ok = "blob", true
+		if tp.Kind() != reflect.Ptr && tp.Kind() != reflect.Interface {
+			val, err := reflect.Zero(tp).Interface().(driver.Valuer).Value()
+			if err == nil {
+				switch val.(type) {
+				case int64, bool:
+					typeStr = "integer"
+				case float64:
+					typeStr = "real"
+				case string:
+					typeStr = "text"
+				case time.Time:
+					typeStr = "datetime"
+				}
+			}
+		}
+	}
+	return
+}
+
+var glCollateRe = regexp.MustCompile("^\\w+$")
+
+var glIdentRe = regexp.MustCompile("^[A-Za-z_]\\w*$")
 
-// Tokenize index tag and store for later sorting and assembling
-func processIndex(tagStr, fldStr string, idxMap map[string]idxListType) (err error) {
-	// tagStr looks like ``, `db_index:"name1"` or `db_index:"loc5 name2"
+var glFkRe = regexp.MustCompile("^\\s*(\\w+)\\s*\\(\\s*(\\w+)\\s*\\)(?:\\s+(?i:(cascade)))?\\s*$")
+
+var glOverrideRe = regexp.MustCompile("^[A-Za-z][\\w ]*(?:\\(\\s*\\d+\\s*(?:,\\s*\\d+\\s*)?\\))?[\\w ]*$")
+
+// Tokenize index tag and store for later sorting and assembling. Segments
+// marked with a leading "!" are stored in uniqueMap rather than idxMap.
+func processIndex(tagStr, fldStr string, idxMap, uniqueMap map[string]idxListType) (err error) {
+	// tagStr looks like ``, `db_index:"name1"`, `db_index:"loc5, name2"`,
+	// `db_index:"!email1, !email2"` or `db_unique:"email1 NOCASE"`
 	if len(tagStr) > 0 {
 		var segList, pairList []string
 		var sortStr string
 		segList = strings.Split(tagStr, ",")
 		for _, str := range segList {
 			if err == nil {
+				m := idxMap
+				if segStr := strings.TrimSpace(str); strings.HasPrefix(segStr, "!") {
+					m = uniqueMap
+					str = segStr[1:]
+				}
 				pairList = glIdxRe.FindStringSubmatch(str)
 				if pairList != nil {
-					sortStr = pairList[1]
-					idxMap[sortStr] = append(idxMap[sortStr],
-						idxType{nameStr: pairList[2], fldStr: fldStr})
+					var seq int
+					// Compare sequence numbers as integers so that, for
+					// example, "loc10" follows "loc9"
+					seq, err = strconv.Atoi(pairList[2])
+					if err == nil {
+						sortStr = pairList[1]
+						m[sortStr] = append(m[sortStr],
+							idxType{seq: seq, fldStr: fldStr, collateStr: pairList[3]})
+					} else {
+						err = fmt.Errorf("index sequence must be an integer: %s", strings.TrimSpace(str))
+					}
 				} else {
 					err = fmt.Errorf("malformed index tag: %s", str)
 				}
@@ -146,6 +325,162 @@ func processIndex(tagStr, fldStr string, idxMap map[string]idxListType) (err err
 	return
 }
 
+// defaultValue returns the SQL form of the value of a "db_default" tag for a
+// column of type typeStr. A value with a leading equal sign is an SQL
+// expression that is used verbatim without the sign. Otherwise, the value is a
+// number for integer and real columns and a string, which is quoted, for
+// other columns.
+func defaultValue(tagStr, typeStr string) (sqlStr string, err error) {
+	if len(tagStr) > 0 {
+		if strings.HasPrefix(tagStr, "=") {
+			sqlStr = tagStr[1:]
+			if len(strings.TrimSpace(sqlStr)) == 0 {
+				err = errors.New(`empty expression in "db_default" tag`)
+			}
+		} else if typeStr == "integer" || typeStr == "real" {
+			_, err = strconv.ParseFloat(tagStr, 64)
+			if err == nil {
+				sqlStr = tagStr
+			} else {
+				err = fmt.Errorf("default value %s of %s column is not a number", tagStr, typeStr)
+			}
+		} else {
+			sqlStr = "'" + strings.Replace(tagStr, "'", "''", -1) + "'"
+		}
+	}
+	return
+}
+
+// zeroValue returns the value of a "db_zero" tag, converted to the type tp of
+// the tagged field. An error occurs if tp is not a number or string type or if
+// tagStr cannot be represented in it.
+func zeroValue(tagStr string, tp reflect.Type) (val interface{}, err error) {
+	vl := reflect.New(tp).Elem()
+	switch tp.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(tagStr, 10, tp.Bits())
+		vl.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(tagStr, 10, tp.Bits())
+		vl.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(tagStr, tp.Bits())
+		vl.SetFloat(n)
+	case reflect.String:
+		vl.SetString(tagStr)
+	default:
+		return nil, fmt.Errorf(`type %s is not a number or string and cannot be tagged "db_zero"`,
+			tp.String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`"db_zero" value %s cannot be stored in type %s`, tagStr, tp.String())
+	}
+	if vl.IsZero() {
+		return nil, fmt.Errorf(`"db_zero" value %s is the zero value of type %s`, tagStr, tp.String())
+	}
+	return vl.Interface(), nil
+}
+
+// affinity returns the type affinity, one of "integer", "text", "blob", "real"
+// and "numeric", that SQLite gives to a column declared with the type
+// typeStr.
+func affinity(typeStr string) string {
+	str := strings.ToUpper(typeStr)
+	has := func(list ...string) bool {
+		for _, sub := range list {
+			if strings.Contains(str, sub) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("INT"):
+		return "integer"
+	case has("CHAR", "CLOB", "TEXT"):
+		return "text"
+	case has("BLOB") || len(strings.TrimSpace(str)) == 0:
+		return "blob"
+	case has("REAL", "FLOA", "DOUB"):
+		return "real"
+	}
+	return "numeric"
+}
+
+// overrideType returns the column type given after the column name in a "db"
+// tag, for example "varchar(255)" in `db:"email,varchar(255)"`, for a field
+// that would otherwise be declared with typeStr. An error occurs if the
+// override is malformed or if values of the field could not be retrieved from
+// a column of that type: numbers cannot be stored in text or blob columns and
+// timestamps need a type that contains DATE or TIME.
+func overrideType(tagStr, typeStr string) (overrideStr string, err error) {
+	overrideStr = strings.TrimSpace(tagStr)
+	if !glOverrideRe.MatchString(overrideStr) {
+		return "", fmt.Errorf("malformed column type override: %s", tagStr)
+	}
+	var ok bool
+	switch typeStr {
+	case "integer", "real":
+		aff := affinity(overrideStr)
+		ok = aff != "text" && aff != "blob"
+	case "datetime":
+		str := strings.ToUpper(overrideStr)
+		ok = strings.Contains(str, "DATE") || strings.Contains(str, "TIME")
+	default:
+		ok = true
+	}
+	if !ok {
+		err = fmt.Errorf("column type override %s conflicts with column type %s", overrideStr, typeStr)
+	}
+	return
+}
+
+// flatten appends to list the fields of the structure type tp. The fields of
+// an embedded structure that has no tags of its own are appended in its place,
+// with indexes that lead to them from the outermost structure. index is the
+// index of tp within the outermost structure.
+func flatten(tp reflect.Type, index []int, list *sfListType) (err error) {
+	for j := 0; j < tp.NumField() && err == nil; j++ {
+		sf := tp.Field(j)
+		sf.Index = append(append([]int{}, index...), sf.Index...)
+		if sf.Anonymous && len(sf.Tag) == 0 {
+			switch {
+			case sf.Type.Kind() == reflect.Struct:
+				err = flatten(sf.Type, sf.Index, list)
+				continue
+			case sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct:
+				// Tagged fields cannot be reached through a nil pointer
+				var subList sfListType
+				err = flatten(sf.Type.Elem(), nil, &subList)
+				for _, sub := range subList {
+					if err == nil && len(sub.Tag.Get("db")+sub.Tag.Get("db_primary")) > 0 {
+						err = fmt.Errorf("embedded structure pointer %s has tagged fields and is not "+
+							"supported; embed the structure itself", sf.Type.String())
+					}
+				}
+				continue
+			}
+		}
+		list.append(sf)
+	}
+	return
+}
+
+// fieldPath returns the name, qualified by the names of any embedded
+// structures that contain it, of the field of tp identified by index.
+func fieldPath(tp reflect.Type, index []int) string {
+	var list []string
+	for _, j := range index {
+		sf := tp.Field(j)
+		list = append(list, sf.Name)
+		tp = sf.Type
+	}
+	return strings.Join(list, ".")
+}
+
 // describe collects meta information, for example field types and SQL
 // names, from the passed-in record.
 func describe(recTp reflect.Type) (dsc DscType, err error) {
@@ -155,39 +490,177 @@ func describe(recTp reflect.Type) (dsc DscType, err error) {
 	errorf := func(fmtStr string, args ...interface{}) {
 		err = fmt.Errorf(fmtStr, args...)
 	}
-	if recTp.Kind() == reflect.Struct {
+	if recTp != nil && recTp.Kind() == reflect.Struct {
 		var typeOk bool
 		dsc.recTp = recTp
 		var sfList sfListType
 		var primaryStr, sqlStr, tblStr, typeStr string
 		var fldTp reflect.Type
-		var selList, qmList, createList strListType
 		dsc.create.idxMap = make(idxMapType)
+		dsc.create.uniqueMap = make(idxMapType)
 		dsc.nameMap = make(map[string]reflect.StructField)
-		for j := 0; j < recTp.NumField(); j++ {
-			sfList.append(recTp.Field(j))
-		}
+		dsc.trimMap = make(map[string]bool)
+		dsc.autoMap = make(map[string]bool)
+		dsc.zeroMap = make(map[string]interface{})
+		dsc.jsonMap = make(map[string]bool)
+		err = flatten(recTp, nil, &sfList)
 		for _, sf := range sfList {
 			if err == nil {
 				fldTp = sf.Type
 				sqlStr = sf.Tag.Get("db")
+				var overrideStr string
+				if pos := strings.Index(sqlStr, ","); pos >= 0 {
+					overrideStr = sqlStr[pos+1:]
+					sqlStr = strings.TrimSpace(sqlStr[:pos])
+				}
 				if len(sqlStr) > 0 {
 					if sqlStr == "*" {
 						sqlStr = sf.Name
 					}
 					// fmt.Printf("Processing field of type %s\n", fldTp.String())
 					typeStr, typeOk = typeMap[fldTp.String()]
-					if typeOk {
+					if len(sf.Tag.Get("db_json")) > 0 {
+						// Any value that can be marshaled is stored as text
+						typeStr, typeOk = "text", true
+						dsc.jsonMap[sqlStr] = true
+					} else if !typeOk {
+						typeStr, typeOk = valuerType(fldTp)
+						if !typeOk && fldTp.Kind() != reflect.Slice {
+							// Named type, for example "type statusType int"
+							typeStr, typeOk = typeMap[fldTp.Kind().String()]
+						} else if !typeOk && fldTp.Elem().Kind() == reflect.Uint8 {
+							// Named byte slice, for example "type hashType []byte"
+							typeStr, typeOk = "blob", true
+						}
+					}
+					if prevSf, dup := dsc.nameMap[sqlStr]; dup {
+						errorf(`column "%s" is used by both field %s and field %s`,
+							sqlStr, fieldPath(recTp, prevSf.Index), fieldPath(recTp, sf.Index))
+					} else if typeOk {
 						dsc.nameMap[sqlStr] = sf
-						createList.appendf("%s %s", sqlStr, typeStr)
-						err = processIndex(sf.Tag.Get("db_index"), sqlStr, dsc.create.idxMap)
+						dsc.create.typeList.append(typeStr)
+						if len(overrideStr) > 0 {
+							overrideStr, err = overrideType(overrideStr, typeStr)
+							if err != nil {
+								errorf("field %s: %s", sf.Name, err)
+							}
+						}
+						dsc.create.overrideList.append(overrideStr)
+						if err == nil {
+							err = processIndex(sf.Tag.Get("db_index"), sqlStr, dsc.create.idxMap,
+								dsc.create.uniqueMap)
+						}
+						if err == nil {
+							uniqueStr := sf.Tag.Get("db_unique")
+							if uniqueStr == "*" {
+								// Single-column index named after the column
+								dsc.create.uniqueMap[sqlStr] = append(dsc.create.uniqueMap[sqlStr],
+									idxType{seq: 1, fldStr: sqlStr})
+							} else {
+								err = processIndex(uniqueStr, sqlStr, dsc.create.uniqueMap, dsc.create.uniqueMap)
+							}
+						}
+						if err == nil {
+							collateStr := sf.Tag.Get("db_collate")
+							if len(collateStr) == 0 || glCollateRe.MatchString(collateStr) {
+								dsc.create.collateList.append(collateStr)
+							} else {
+								errorf("malformed collation: %s", collateStr)
+							}
+						}
+						if err == nil {
+							notNull := len(sf.Tag.Get("db_notnull")) > 0
+							if notNull && (fldTp.Kind() == reflect.Ptr ||
+								reflect.PtrTo(fldTp).Implements(glScannerTp)) {
+								errorf(`field %s of type %s can hold NULL and cannot be tagged "db_notnull"`,
+									sf.Name, fldTp.String())
+							} else {
+								dsc.create.notNullList = append(dsc.create.notNullList, notNull)
+							}
+						}
+						if err == nil {
+							trimStr := sf.Tag.Get("db_trim")
+							if len(trimStr) > 0 {
+								if fldTp.Kind() != reflect.String {
+									errorf(`field %s of type %s is not a string and cannot be tagged "db_trim"`,
+										sf.Name, fldTp.String())
+								} else if trimStr == "*" || trimStr == "lower" {
+									dsc.trimMap[sqlStr] = trimStr == "lower"
+								} else {
+									errorf(`malformed "db_trim" tag: %s`, trimStr)
+								}
+							}
+						}
+						if err == nil {
+							autoCreate := len(sf.Tag.Get("db_autocreate")) > 0
+							autoUpdate := len(sf.Tag.Get("db_autoupdate")) > 0
+							if autoCreate || autoUpdate {
+								if fldTp == glTimeTp || fldTp == glTimePtrTp {
+									dsc.autoMap[sqlStr] = autoUpdate
+								} else {
+									errorf(`field %s of type %s is not a timestamp and cannot be tagged `+
+										`"db_autocreate" or "db_autoupdate"`, sf.Name, fldTp.String())
+								}
+							}
+						}
+						if err == nil {
+							zeroStr := sf.Tag.Get("db_zero")
+							if len(zeroStr) > 0 {
+								var val interface{}
+								val, err = zeroValue(zeroStr, fldTp)
+								if err == nil {
+									dsc.zeroMap[sqlStr] = val
+								} else {
+									errorf("field %s: %s", sf.Name, err)
+								}
+							}
+						}
+						if err == nil {
+							fkStr := sf.Tag.Get("db_fk")
+							if len(fkStr) > 0 {
+								m := glFkRe.FindStringSubmatch(fkStr)
+								if m != nil {
+									dsc.create.fkList = append(dsc.create.fkList,
+										fkType{nameStr: sqlStr, tblStr: m[1], colStr: m[2], cascade: len(m[3]) > 0})
+								} else {
+									errorf(`malformed "db_fk" tag: %s`, fkStr)
+								}
+							}
+						}
+						if err == nil && len(sf.Tag.Get("db_softdelete")) > 0 {
+							if len(dsc.soft.nameStr) > 0 {
+								errorstr(`multiple occurrence of "db_softdelete" tag`)
+							} else if fldTp.Kind() == reflect.Bool || fldTp == glTimeTp || fldTp == glTimePtrTp {
+								dsc.soft.nameStr = sqlStr
+								dsc.soft.sf = sf
+							} else {
+								errorf(`field %s of type %s cannot be tagged "db_softdelete"; `+
+									`use bool, time.Time or *time.Time`, sf.Name, fldTp.String())
+							}
+						}
+						if err == nil {
+							var defStr string
+							defStr, err = defaultValue(sf.Tag.Get("db_default"), typeStr)
+							dsc.create.defaultList.append(defStr)
+						}
+						if err == nil {
+							checkStr := sf.Tag.Get("db_check")
+							if len(checkStr) > 0 && len(strings.TrimSpace(checkStr)) == 0 {
+								errorstr(`empty expression in "db_check" tag`)
+							} else {
+								dsc.create.checkList.append(strings.TrimSpace(checkStr))
+							}
+						}
 						if err == nil {
 							dsc.insert.sfList.append(sf)
 							dsc.insert.nameList.append(sqlStr)
-							qmList.append("?")
 							dsc.sel.typeStrList.append(typeStr)
-							selList.append(sqlStr)
+							dsc.sel.nameList.append(sqlStr)
 							dsc.sel.sfList.append(sf)
+							if len(sf.Tag.Get("db_primary")) > 0 {
+								dsc.key.nameList.append(sqlStr)
+								dsc.key.sfList.append(sf)
+							}
 						}
 					} else {
 						errorf("database does not support fields of type %s", fldTp.String())
@@ -197,11 +670,18 @@ func describe(recTp reflect.Type) (dsc DscType, err error) {
 					if len(primaryStr) > 0 {
 						if !dsc.idPresent {
 							if fldTp.Kind() == reflect.Int64 {
-								selList.append("rowid") // Warning: SQLite3ism
+								dsc.sel.nameList.append("")
 								dsc.sel.sfList.append(sf)
 								dsc.sel.typeStrList.appendf("%v", sf.Type.Kind())
 								dsc.idSf = sf
 								dsc.idPresent = true
+								if primaryStr != "*" {
+									if glIdentRe.MatchString(primaryStr) {
+										dsc.idNameStr = primaryStr
+									} else {
+										errorf(`malformed "db_primary" tag: %s`, primaryStr)
+									}
+								}
 							} else {
 								errorf("expecting int64 for id, got %v", fldTp.Kind())
 							}
@@ -228,14 +708,32 @@ func describe(recTp reflect.Type) (dsc DscType, err error) {
 			} else if len(dsc.tblStr) == 0 {
 				errorstr(`missing "db_table" tag`)
 			} else {
-				dsc.insert.qmStr = qmList.join()
-				dsc.insert.nameStr = dsc.insert.nameList.join()
-				dsc.create.nameTypeStr = createList.join()
-				for _, v := range dsc.create.idxMap {
+				// Order the columns of each index by sequence number. The
+				// slices are sorted in place so the maps see the result.
+				// Indexes are visited in name order so that the first
+				// error reported does not vary from run to run.
+				for _, k := range dsc.create.idxMap.names() {
+					v := dsc.create.idxMap[k]
+					sort.Sort(v)
+					if seq, ok := v.duplicate(); ok && err == nil {
+						errorf("duplicate index sequence %d for index %s", seq, k)
+					}
+				}
+				for _, k := range dsc.create.uniqueMap.names() {
+					v := dsc.create.uniqueMap[k]
 					sort.Sort(v)
-					// fmt.Printf("%s %v\n", k, v)
+					if err == nil {
+						if _, ok := dsc.create.idxMap[k]; ok {
+							errorf(`index "%s" is declared both unique and not unique`, k)
+						} else if seq, ok := v.duplicate(); ok {
+							errorf("duplicate index sequence %d for index %s", seq, k)
+						}
+					}
 				}
-				dsc.sel.nameStr = selList.join()
+			}
+			if err == nil {
+				dsc.dialect = SQLiteDialect{}
+				dsc.assemble()
 				// dump(dsc)
 			}
 		}
@@ -246,14 +744,226 @@ func describe(recTp reflect.Type) (dsc DscType, err error) {
 	return
 }
 
+// assemble generates, using the receiver's dialect, the portions of SQL
+// commands that do not vary from call to call.
+func (dsc *DscType) assemble() {
+	var list strListType
+	dsc.tblIdentStr = dsc.ident(dsc.tblStr)
+	dsc.insert.nameStr = strings.Join(dsc.identList(dsc.insert.nameList), ", ")
+	for j := range dsc.insert.nameList {
+		list.append(dsc.dialect.PlaceholderMark(j + 1))
+	}
+	dsc.insert.qmStr = list.join()
+	list = nil
+	if dsc.idPresent {
+		autoStr := dsc.dialect.AutoIncrementType()
+		if len(autoStr) == 0 && len(dsc.idNameStr) > 0 {
+			// Named column that the database assigns like its implicit key
+			autoStr = dsc.dialect.ColumnType("integer") + " PRIMARY KEY"
+		}
+		if len(autoStr) > 0 {
+			list.appendf("%s %s", dsc.ident(dsc.primaryKeyColumn()), autoStr)
+		}
+	}
+	dsc.create.colDefList = nil
+	for j, nameStr := range dsc.insert.nameList {
+		defStr := dsc.ident(nameStr) + " " + dsc.columnType(j)
+		if dsc.create.notNullList[j] {
+			defStr += " NOT NULL"
+		}
+		if len(dsc.create.defaultList[j]) > 0 {
+			defStr += " DEFAULT " + dsc.create.defaultList[j]
+		}
+		if len(dsc.create.collateList[j]) > 0 {
+			defStr += prePad(dsc.dialect.CollateClause(dsc.create.collateList[j]))
+		}
+		if len(dsc.create.checkList[j]) > 0 {
+			defStr += " CHECK (" + dsc.create.checkList[j] + ")"
+		}
+		dsc.create.colDefList.append(defStr)
+		list.append(defStr)
+	}
+	if len(dsc.key.nameList) > 0 {
+		if len(list) > len(dsc.insert.nameList) {
+			// Primary key already declared for the identifier column
+			list.appendf("UNIQUE (%s)", strings.Join(dsc.identList(dsc.key.nameList), ", "))
+		} else {
+			list.appendf("PRIMARY KEY (%s)", strings.Join(dsc.identList(dsc.key.nameList), ", "))
+		}
+	}
+	for _, fk := range dsc.create.fkList {
+		str := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+			dsc.ident(fk.nameStr), dsc.ident(fk.tblStr), dsc.ident(fk.colStr))
+		if fk.cascade {
+			str += " ON DELETE CASCADE"
+		}
+		list.append(str)
+	}
+	dsc.create.nameTypeStr = list.join()
+	list = nil
+	for _, nameStr := range dsc.sel.nameList {
+		if len(nameStr) == 0 {
+			nameStr = dsc.primaryKeyColumn()
+		}
+		list.append(dsc.ident(nameStr))
+	}
+	dsc.sel.nameStr = list.join()
+}
+
+// ident returns str in the form it needs to take in SQL, that is, quoted if
+// it is a reserved word of the receiver's dialect.
+func (dsc DscType) ident(str string) string {
+	if dsc.dialect.ReservedWord(str) {
+		return dsc.dialect.QuoteIdent(str)
+	}
+	return str
+}
+
+// identList returns the names in list in the form returned by ident().
+func (dsc DscType) identList(list []string) (identList []string) {
+	for _, str := range list {
+		identList = append(identList, dsc.ident(str))
+	}
+	return
+}
+
+// primaryKeyColumn returns the name of the column that holds the field tagged
+// with "db_primary" (and no "db" tag).
+func (dsc DscType) primaryKeyColumn() string {
+	if len(dsc.idNameStr) > 0 {
+		return dsc.idNameStr
+	}
+	return dsc.dialect.PrimaryKeyColumn()
+}
+
+// returningStr returns the clause, if any, that is appended to insertion
+// commands in order to retrieve the assigned identifier.
+func (dsc DscType) returningStr() (str string) {
+	if dsc.idPresent {
+		str = dsc.dialect.ReturningID()
+		if len(str) > 0 && len(dsc.idNameStr) > 0 {
+			str = "RETURNING " + dsc.ident(dsc.primaryKeyColumn())
+		}
+	}
+	return
+}
+
+// WithStrict returns a copy of the receiver that, if strict is true, declares
+// its table with the STRICT keyword of SQLite 3.37 and later. In a strict
+// table, SQLite rejects values that do not match the declared column type
+// rather than silently converting them. Column types that are not permitted in
+// strict tables, such as datetime, are declared with a permitted equivalent.
+// The receiver itself is not modified.
+func (dsc DscType) WithStrict(strict bool) DscType {
+	dsc.create.strict = strict
+	dsc.assemble()
+	return dsc
+}
+
+// WithDialect returns a copy of the receiver that generates SQL commands
+// for the dialect d. The receiver itself is not modified.
+func (dsc DscType) WithDialect(d Dialect) DscType {
+	dsc.dialect = d
+	dsc.assemble()
+	return dsc
+}
+
+// placeholderDialect is a Dialect whose parameter markers are generated by
+// a function. See DscType.WithPlaceholder().
+type placeholderDialect struct {
+	Dialect
+	mark func(i int) string
+}
+
+// PlaceholderMark implements Dialect.
+func (d placeholderDialect) PlaceholderMark(i int) string {
+	return d.mark(i)
+}
+
+// WithPlaceholder returns a copy of the receiver whose commands mark the i'th
+// parameter, starting with 1, with the value returned by mark(i), for example
+// "$1" or ":p1". The other details of the receiver's dialect are retained.
+// This affects the commands generated by methods such as InsertStr(),
+// UpdateStr() and UpsertStr() and the conditions assembled by Where(); tail
+// strings passed by the caller are used as they are. If mark is nil, the
+// receiver's dialect is restored. The receiver itself is not modified.
+func (dsc DscType) WithPlaceholder(mark func(i int) string) DscType {
+	if d, ok := dsc.dialect.(placeholderDialect); ok {
+		dsc.dialect = d.Dialect
+	}
+	if mark != nil {
+		dsc.dialect = placeholderDialect{Dialect: dsc.dialect, mark: mark}
+	}
+	dsc.assemble()
+	return dsc
+}
+
+// WithTable returns a copy of the receiver that is associated with the table
+// nameStr rather than the one named in the "db_table" tag. This lets one
+// record structure serve several tables with the same columns, for example
+// "events_2023" and "events_2024". Index names, which are derived from the
+// table name, change accordingly. The receiver itself is not modified.
+func (dsc DscType) WithTable(nameStr string) DscType {
+	dsc.tblStr = nameStr
+	dsc.assemble()
+	return dsc
+}
+
 // SelectStr returns a command string suitable for retrieving records from the
 // database table that is associated with the receiver. tailStr is any SQL that
 // can follow the main select portion of the command. Parameters are indicated
 // by a question mark and will be included, in the same order, in the call to
-// SelectArg().
+// SelectArg(). If the record structure has a field tagged "db_softdelete",
+// soft-deleted records are excluded; see SelectWithDeletedStr().
 func (dsc DscType) SelectStr(tailStr string) string {
 	return fmt.Sprintf("SELECT %s FROM %s%s;",
-		dsc.sel.nameStr, dsc.tblStr, prePad(tailStr))
+		dsc.sel.nameStr, dsc.fromStr(false), prePad(tailStr))
+}
+
+// CountStr returns a command string suitable for counting the records in the
+// database table associated with the receiver that satisfy tailStr. tailStr
+// is used as in SelectStr().
+func (dsc DscType) CountStr(tailStr string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s%s;", dsc.fromStr(false), prePad(tailStr))
+}
+
+// ExistsStr returns a command string suitable for determining whether any
+// record in the database table associated with the receiver satisfies
+// tailStr. tailStr is used as in SelectStr(). The command returns a single row
+// with a single boolean column.
+func (dsc DscType) ExistsStr(tailStr string) string {
+	return fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s%s);", dsc.fromStr(false), prePad(tailStr))
+}
+
+// ScalarGroupedStr returns a command string that evaluates selectExpr, for
+// example "COUNT(*)", over the groups of records in the table associated with
+// the receiver. The records that satisfy tailStr, which may be empty or a
+// WHERE clause, are grouped by the columns in groupByStr, and the groups that
+// satisfy havingStr, unless it is empty, are the rows to which selectExpr
+// applies. selectExpr may refer to the grouping columns. Parameters of
+// tailStr precede those of havingStr.
+func (dsc DscType) ScalarGroupedStr(selectExpr, groupByStr, havingStr, tailStr string) string {
+	if len(havingStr) > 0 {
+		havingStr = " HAVING " + havingStr
+	}
+	return fmt.Sprintf("SELECT %s FROM (SELECT %s FROM %s%s GROUP BY %s%s) AS grp;",
+		selectExpr, groupByStr, dsc.fromStr(false), prePad(tailStr), groupByStr, havingStr)
+}
+
+// GroupedStr returns a command string that retrieves one row for each group
+// of records in the table associated with the receiver. The records that
+// satisfy tailStr, which may be empty or a WHERE clause, are grouped by the
+// columns in groupByStr, and the groups that satisfy havingStr, unless it is
+// empty, are retrieved in the order of groupByStr. selectExpr lists the
+// retrieved columns, typically grouping columns and aggregates with aliases,
+// for example "author, COUNT(*) AS cnt". Parameters of tailStr precede those
+// of havingStr. See WrapType.QueryGrouped().
+func (dsc DscType) GroupedStr(selectExpr, groupByStr, havingStr, tailStr string) string {
+	if len(havingStr) > 0 {
+		havingStr = " HAVING " + havingStr
+	}
+	return fmt.Sprintf("SELECT %s FROM %s%s GROUP BY %s%s ORDER BY %s;",
+		selectExpr, dsc.fromStr(false), prePad(tailStr), groupByStr, havingStr, groupByStr)
 }
 
 // SelectArg returns a slice of interface values, one for each table field,
@@ -261,17 +971,19 @@ func (dsc DscType) SelectStr(tailStr string) string {
 // once for each selected record variable. Consequently, this function can be
 // called once with a record declared outside of a retrieval loop. Within the
 // loop, calls to Scan() with the expanded interface slice will repeatedly
-// update the same record.
+// update the same record. For a descriptor generated by DescribeColumns(),
+// recPtr is a map or slice as described there.
 func (dsc DscType) SelectArg(recPtr interface{}) (argList []interface{}, err error) {
+	if dsc.colList != nil {
+		return dsc.columnArg(recPtr)
+	}
 	ptrVl := reflect.ValueOf(recPtr)
 	kd := ptrVl.Kind()
 	if kd == reflect.Ptr {
 		recVl := ptrVl.Elem()
 		if recVl.Type() == dsc.recTp {
-			var sf reflect.StructField
-			for _, sf = range dsc.sel.sfList {
-				argList = append(argList, recVl.FieldByIndex(sf.Index).Addr().Interface())
-			}
+			argList = make([]interface{}, len(dsc.sel.sfList))
+			dsc.selectFill(recVl, argList)
 		} else {
 			err = fmt.Errorf("passed in record (%s) for select does not match descriptor (%s)",
 				recVl.Type().String(), dsc.recTp.String())
@@ -282,99 +994,935 @@ func (dsc DscType) SelectArg(recPtr interface{}) (argList []interface{}, err err
 	return
 }
 
-// CreateStr returns a command string suitable for creating the database table
-// that is associated with the receiver.
-func (dsc DscType) CreateStr() (createStr string, idxStrList []string) {
-	createStr = fmt.Sprintf("CREATE TABLE %s (%s);", dsc.tblStr, dsc.create.nameTypeStr)
-	for k, v := range dsc.create.idxMap {
-		var list strListType
-		for _, idx := range v {
-			list.append(idx.fldStr)
-		}
-		idxStrList = append(idxStrList, fmt.Sprintf("CREATE INDEX %s_%s ON %s (%s)",
-			dsc.tblStr, k, dsc.tblStr, list.join()))
+// selectFill stores in argList the addresses of the selected fields of the
+// record recVl. argList must have one element for each selected field.
+func (dsc DscType) selectFill(recVl reflect.Value, argList []interface{}) {
+	for j, sf := range dsc.sel.sfList {
+		argList[j] = dsc.scanTarget(recVl, sf, dsc.sel.nameList[j])
 	}
-	return
 }
 
-func (dsc DscType) updateNames(fldNames ...string) []string {
-	if len(fldNames) == 0 {
-		fldNames = dsc.insert.nameList
-	} else if fldNames[0] == "*" {
-		fldNames = dsc.insert.nameList
+// scanTarget returns the value into which the field sf of the record recVl is
+// scanned. nameStr is the name of the column associated with sf, or an empty
+// string for the primary key column.
+func (dsc DscType) scanTarget(recVl reflect.Value, sf reflect.StructField, nameStr string) interface{} {
+	fldVl := recVl.FieldByIndex(sf.Index)
+	if sf.Type == glTimeTp && len(nameStr) > 0 && nameStr == dsc.soft.nameStr {
+		// NULL indicates a record that has not been soft-deleted
+		return timeScanType{fldVl: fldVl, nullZero: true}
 	}
-	return fldNames
+	if dsc.jsonMap[nameStr] {
+		return jsonScanType{fldVl: fldVl}
+	}
+	if zero, ok := dsc.zeroMap[nameStr]; ok {
+		return zeroScanType{fldVl: fldVl, zero: zero}
+	}
+	if sf.Type.Kind() == reflect.Bool && !reflect.PtrTo(sf.Type).Implements(glScannerTp) {
+		return boolScanType{fldVl: fldVl}
+	}
+	if dsc.create.strict && (sf.Type == glTimeTp || sf.Type == glTimePtrTp) {
+		// Strict tables store timestamps as text
+		return timeScanType{fldVl: fldVl}
+	}
+	return fldVl.Addr().Interface()
 }
 
-// UpdateStr returns a command string suitable for updating records into
-// the table associated with the receiver.
-func (dsc DscType) UpdateStr(fldNames ...string) string {
-	fldNames = dsc.updateNames(fldNames...)
-	var eqList strListType
-	for _, nm := range fldNames {
-		// fmt.Printf("sf.Name [%s], %v\n", sf.Name, fldMap[sf.Name])
-		eqList.appendf("%s = ?", nm)
+// BindPositional returns scan targets for the first columnCount fields of the
+// record pointed to by recPtr, in the order in which SelectStr() selects them.
+// This allows a hand-written query to be scanned into the record without
+// reference to column names. Positional binding is fragile: the query must
+// select its columns in exactly this order and with compatible types, and
+// reordering or inserting fields in the record structure silently changes the
+// binding. An error occurs if columnCount is less than one or exceeds the
+// number of selected fields.
+func (dsc DscType) BindPositional(recPtr interface{}, columnCount int) (argList []interface{}, err error) {
+	if columnCount < 1 || columnCount > len(dsc.sel.sfList) {
+		err = fmt.Errorf("column count %d is outside the range 1 to %d of selected fields",
+			columnCount, len(dsc.sel.sfList))
+	} else {
+		argList, err = dsc.SelectArg(recPtr)
+		if err == nil {
+			argList = argList[:columnCount]
+		}
 	}
-	return fmt.Sprintf("UPDATE %s SET %s WHERE rowid = ?;", dsc.tblStr, eqList.join())
+	return
 }
 
-// UpdateArg returns a slice of interface values that can be expanded in an SQL
-// call. This function needs to be called once for each updated record. The
-// passed-in val can be a properly tagged structure variable or a pointer to
-// one. Additionally, the field names that are passed in must be the same ones,
-// in the same order, as passed to UpdateStr(). The record to update is
-// identified by the field tagged 'db_primary'. That field must contain the
-// same identifier as it had when retrieved from the database.
-func (dsc DscType) UpdateArg(rec interface{}, fldNames ...string) (argList []interface{}, err error) {
-	if dsc.idPresent {
-		vl := reflect.ValueOf(rec)
-		if vl.Kind() == reflect.Ptr {
-			vl = vl.Elem()
-		}
-		if vl.Type() == dsc.recTp {
-			fldNames = dsc.updateNames(fldNames...)
-			// var list sfListType
-			var ok bool
-			var sf reflect.StructField
-			for _, nm := range fldNames {
-				// See InsertArg for correct way of doing this
-				if err == nil {
-					// fmt.Printf("sf.Name [%s], %v\n", sf.Name, fldMap[sf.Name])
-					sf, ok = dsc.nameMap[nm]
-					if ok {
-						argList = append(argList, vl.FieldByIndex(sf.Index).Interface())
-						// list.append(sf)
-					} else {
-						err = fmt.Errorf("field name \"%s\" not in structure", nm)
-					}
-				}
+// BindColumns returns scan targets for a row of the result of a hand-written
+// query or a view whose columns are named, in order, in cols, for example as
+// returned by sql.Rows.Columns(). Each column with the name of a column
+// selected by SelectStr() is bound to the corresponding field of the record
+// pointed to by recPtr; fields whose columns are absent are not modified. The
+// remaining columns are extra. If extra is not nil, it is called with a slice
+// holding one scan target, a *interface{}, for each extra column in the order
+// in which the columns appear in cols. It may replace these targets with
+// pointers to its own variables. The targets are used in place of the record
+// fields at the positions of the extra columns. A column name that occurs more
+// than once is bound to the record only at its first occurrence.
+func (dsc DscType) BindColumns(recPtr interface{}, cols []string,
+	extra func(extra []interface{})) (argList []interface{}, err error) {
+	var fldList []interface{}
+	fldList, err = dsc.SelectArg(recPtr)
+	if err == nil {
+		posMap := make(map[string]int, len(dsc.sel.nameList))
+		for j, nameStr := range dsc.sel.nameList {
+			if len(nameStr) == 0 {
+				nameStr = dsc.primaryKeyColumn()
 			}
-			if err == nil {
-				argList = append(argList, vl.FieldByIndex(dsc.idSf.Index).Interface())
+			posMap[nameStr] = j
+		}
+		var extraList []interface{}
+		var extraPosList []int
+		argList = make([]interface{}, len(cols))
+		for j, nameStr := range cols {
+			pos, ok := posMap[nameStr]
+			if ok {
+				argList[j] = fldList[pos]
+				delete(posMap, nameStr)
+			} else {
+				extraList = append(extraList, new(interface{}))
+				extraPosList = append(extraPosList, j)
 			}
-		} else {
-			err = fmt.Errorf("value passed into update must be a structure (or pointer to a structure) "+
-				"of type %s", dsc.recTp.String())
 		}
-	} else {
-		err = errors.New("update requires structure with primary ID")
+		if extra != nil && len(extraList) > 0 {
+			extra(extraList)
+		}
+		for j, pos := range extraPosList {
+			argList[pos] = extraList[j]
+		}
 	}
 	return
 }
 
-// InsertStr returns a command string suitable for inserting new records into
-// the table associated with the receiver.
-func (dsc DscType) InsertStr() string {
-	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);",
-		dsc.tblStr, dsc.insert.nameStr, dsc.insert.qmStr)
+// SelectColsStr is like SelectStr() except that only the columns named in
+// cols are retrieved. The names are those given in the "db" tags of the record
+// structure.
+func (dsc DscType) SelectColsStr(cols []string, tailStr string) string {
+	return fmt.Sprintf("SELECT %s FROM %s%s;",
+		strings.Join(dsc.identList(cols), ", "), dsc.fromStr(false), prePad(tailStr))
+}
+
+// SelectColsArg is like SelectArg() except that it returns scan targets only
+// for the columns named in cols, in the same order. It is used in conjunction
+// with SelectColsStr(). An error occurs if a name in cols is not the "db" tag
+// of a field in the record structure or if the receiver was generated by
+// DescribeColumns().
+func (dsc DscType) SelectColsArg(recPtr interface{}, cols []string) (argList []interface{}, err error) {
+	if dsc.colList != nil {
+		return nil, fmt.Errorf("descriptor of table %s does not support retrieval of selected columns",
+			dsc.tblStr)
+	}
+	if len(cols) == 0 {
+		return nil, errors.New("at least one column must be selected")
+	}
+	ptrVl := reflect.ValueOf(recPtr)
+	if ptrVl.Kind() == reflect.Ptr && ptrVl.Elem().Type() == dsc.recTp {
+		recVl := ptrVl.Elem()
+		argList = make([]interface{}, len(cols))
+		for j, nameStr := range cols {
+			sf, ok := dsc.nameMap[nameStr]
+			if ok {
+				argList[j] = dsc.scanTarget(recVl, sf, nameStr)
+			} else {
+				return nil, fmt.Errorf("column \"%s\" is not a \"db\" tag of %s",
+					nameStr, dsc.recTp.String())
+			}
+		}
+	} else {
+		err = fmt.Errorf("passed-in value must be a pointer to a structure of type %s",
+			dsc.recTp.String())
+	}
+	return
+}
+
+// sliceValue returns the slice pointed to by slicePtr after confirming that
+// its elements are records of the type associated with the receiver.
+func (dsc DscType) sliceValue(slicePtr interface{}) (sliceVl reflect.Value, err error) {
+	if dsc.colList != nil {
+		err = fmt.Errorf("descriptor of table %s does not support retrieval into a slice", dsc.tblStr)
+		return
+	}
+	ptrVl := reflect.ValueOf(slicePtr)
+	if ptrVl.Kind() == reflect.Ptr && ptrVl.Elem().Kind() == reflect.Slice &&
+		ptrVl.Elem().Type().Elem() == dsc.recTp {
+		sliceVl = ptrVl.Elem()
+	} else {
+		err = fmt.Errorf("passed-in value must be a pointer to a slice of %s",
+			dsc.recTp.String())
+	}
+	return
+}
+
+// mapValue returns the map pointed to by mapPtr, allocating it if it is nil,
+// after confirming that its elements are records of the type associated with
+// the receiver and that its keys are of the kind of the receiver's primary
+// key. keySf describes the primary key field. The primary key must consist of
+// a single field.
+func (dsc DscType) mapValue(mapPtr interface{}) (mapVl reflect.Value, keySf reflect.StructField, err error) {
+	if dsc.idPresent {
+		keySf = dsc.idSf
+	} else if len(dsc.key.sfList) == 1 {
+		keySf = dsc.key.sfList[0]
+	} else {
+		err = fmt.Errorf("table %s does not have a single-field primary key", dsc.tblStr)
+		return
+	}
+	ptrVl := reflect.ValueOf(mapPtr)
+	if ptrVl.Kind() == reflect.Ptr && ptrVl.Elem().Kind() == reflect.Map &&
+		ptrVl.Elem().Type().Elem() == dsc.recTp &&
+		ptrVl.Elem().Type().Key().Kind() == keySf.Type.Kind() {
+		mapVl = ptrVl.Elem()
+		if mapVl.IsNil() {
+			mapVl.Set(reflect.MakeMap(mapVl.Type()))
+		}
+	} else {
+		err = fmt.Errorf("passed-in value must be a pointer to a map of %s keyed by %s",
+			dsc.recTp.String(), keySf.Type.Kind())
+	}
+	return
+}
+
+// CreateStr returns a command string suitable for creating the database table
+// that is associated with the receiver. The commands in idxStrList create the
+// table's indexes and are sorted by index name, so the output is the same from
+// call to call.
+func (dsc DscType) CreateStr() (createStr string, idxStrList []string) {
+	return dsc.createStr("")
+}
+
+// CreateStrIfNotExists is like CreateStr() except that the commands include an
+// IF NOT EXISTS clause so that they succeed, without effect, if the table or
+// indexes already exist. Note that an existing table is left as it is even if
+// its columns differ from those of the receiver; see WrapType.ColumnDrift().
+func (dsc DscType) CreateStrIfNotExists() (createStr string, idxStrList []string) {
+	return dsc.createStr("IF NOT EXISTS")
+}
+
+// createStr implements CreateStr() and CreateStrIfNotExists(). existsStr, if
+// not empty, is inserted before the names of the table and indexes.
+func (dsc DscType) createStr(existsStr string) (createStr string, idxStrList []string) {
+	var strictStr string
+	if dsc.create.strict {
+		strictStr = " STRICT"
+	}
+	createStr = fmt.Sprintf("CREATE TABLE%s %s (%s)%s;", prePad(existsStr), dsc.tblIdentStr,
+		dsc.create.nameTypeStr, strictStr)
+	idxStrList = dsc.indexStrList(existsStr)
+	return
+}
+
+// indexStrList returns the commands of indexStrMap() sorted by index name.
+func (dsc DscType) indexStrList(existsStr string) (idxStrList []string) {
+	mp := dsc.indexStrMap(existsStr)
+	for _, nameStr := range dsc.Indexes() {
+		idxStrList = append(idxStrList, mp[nameStr])
+	}
+	return
+}
+
+// IndexStatements returns the commands that CreateStr() generates for
+// creating the receiver's indexes, sorted by index name. The command at each
+// position creates the index named at the same position by Indexes().
+func (dsc DscType) IndexStatements() []string {
+	return dsc.indexStrList("")
+}
+
+// indexStr returns a command, introduced by cmdStr, for creating the index k
+// on the columns in idxList.
+func (dsc DscType) indexStr(cmdStr, k string, idxList idxListType) string {
+	var list strListType
+	for _, idx := range idxList {
+		if len(idx.collateStr) > 0 {
+			list.append(dsc.ident(idx.fldStr) + prePad(dsc.dialect.CollateClause(idx.collateStr)))
+		} else {
+			list.append(dsc.ident(idx.fldStr))
+		}
+	}
+	return fmt.Sprintf("%s %s ON %s (%s)",
+		cmdStr, dsc.indexName(k), dsc.tblIdentStr, list.join())
+}
+
+// indexStrMap returns the commands that CreateStr() generates for creating
+// the receiver's indexes, keyed by index name. existsStr, if not empty, is
+// inserted before the index names.
+func (dsc DscType) indexStrMap(existsStr string) (mp map[string]string) {
+	mp = make(map[string]string)
+	for k, v := range dsc.create.idxMap {
+		mp[dsc.indexName(k)] = dsc.indexStr("CREATE INDEX"+prePad(existsStr), k, v)
+	}
+	for k, v := range dsc.create.uniqueMap {
+		mp[dsc.indexName(k)] = dsc.indexStr("CREATE UNIQUE INDEX"+prePad(existsStr), k, v)
+	}
+	return
+}
+
+// AddColumnStr returns a command string suitable for adding the column
+// nameStr, as CreateStr() declares it, to the table associated with the
+// receiver. An error occurs if nameStr is not the "db" tag of an inserted
+// field in the record structure.
+func (dsc DscType) AddColumnStr(nameStr string) (cmdStr string, err error) {
+	for j, str := range dsc.insert.nameList {
+		if str == nameStr {
+			cmdStr = fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;",
+				dsc.tblIdentStr, dsc.create.colDefList[j])
+			return
+		}
+	}
+	err = fmt.Errorf("field name \"%s\" not in structure", nameStr)
+	return
+}
+
+// columnType returns the type declared in the commands generated by
+// CreateStr() for the column of the inserted field at position j.
+func (dsc DscType) columnType(j int) string {
+	if len(dsc.create.overrideList[j]) > 0 {
+		return dsc.create.overrideList[j]
+	}
+	typeStr := dsc.create.typeList[j]
+	if dsc.create.strict {
+		str, ok := glStrictTypeMap[typeStr]
+		if ok {
+			typeStr = str
+		}
+	}
+	return dsc.dialect.ColumnType(typeStr)
+}
+
+// typeFamily classifies the column type typeStr, following the SQLite rules
+// of type affinity, as "integer", "real" or "text". An empty string is
+// returned for other types, such as blob and datetime, whose values are not
+// checked for compatibility.
+func typeFamily(typeStr string) string {
+	str := strings.ToUpper(typeStr)
+	switch {
+	case strings.Contains(str, "INT"):
+		return "integer"
+	case strings.Contains(str, "CHAR"), strings.Contains(str, "CLOB"), strings.Contains(str, "TEXT"):
+		return "text"
+	case strings.Contains(str, "REAL"), strings.Contains(str, "FLOA"), strings.Contains(str, "DOUB"):
+		return "real"
+	}
+	return ""
+}
+
+// indexName returns the name of the index k of the receiver's table. If the
+// name exceeds the maximum identifier length of the receiver's dialect, it is
+// truncated and a hash of the full name is appended so that it remains
+// unique.
+func (dsc DscType) indexName(k string) string {
+	nameStr := dsc.tblStr + "_" + k
+	max := dsc.dialect.MaxIdentLength()
+	if max > 0 && len(nameStr) > max {
+		h := fnv.New32a()
+		h.Write([]byte(nameStr))
+		hashStr := fmt.Sprintf("_%08x", h.Sum32())
+		if max > len(hashStr) {
+			nameStr = nameStr[:max-len(hashStr)] + hashStr
+		} else {
+			nameStr = hashStr[1:]
+		}
+	}
+	return nameStr
+}
+
+// Indexes returns the names of the indexes that CreateStr() declares, in
+// sorted order. These are the names that DropStr() and WrapType.IndexDrift()
+// use, and the order matches that of the commands returned by
+// IndexStatements().
+func (dsc DscType) Indexes() (list []string) {
+	for _, idxMap := range []idxMapType{dsc.create.idxMap, dsc.create.uniqueMap} {
+		for k := range idxMap {
+			list = append(list, dsc.indexName(k))
+		}
+	}
+	sort.Strings(list)
+	return
+}
+
+// DropStr returns a command string suitable for removing the database table
+// that is associated with the receiver, along with command strings for
+// removing the indexes that CreateStr() generates. The index commands should
+// be executed before the table command. If ifExists is true, the commands
+// include an IF EXISTS clause so that they succeed even if the table or
+// indexes do not exist.
+func (dsc DscType) DropStr(ifExists bool) (dropStr string, idxStrList []string) {
+	var existsStr string
+	if ifExists {
+		existsStr = "IF EXISTS "
+	}
+	dropStr = fmt.Sprintf("DROP TABLE %s%s;", existsStr, dsc.tblIdentStr)
+	for _, nameStr := range dsc.Indexes() {
+		idxStrList = append(idxStrList, fmt.Sprintf("DROP INDEX %s%s;", existsStr, nameStr))
+	}
+	return
+}
+
+func (dsc DscType) updateNames(fldNames ...string) []string {
+	if len(fldNames) == 0 {
+		fldNames = dsc.insert.nameList
+	} else if fldNames[0] == "*" {
+		fldNames = dsc.insert.nameList
+	} else {
+		// Timestamps maintained on update are always included
+		for _, nameStr := range dsc.insert.nameList {
+			if dsc.autoMap[nameStr] {
+				found := false
+				for _, str := range fldNames {
+					found = found || str == nameStr
+				}
+				if !found {
+					fldNames = append(fldNames[:len(fldNames):len(fldNames)], nameStr)
+				}
+			}
+		}
+	}
+	return fldNames
+}
+
+// keyWhereStr returns the condition that identifies a record in update
+// commands. Placeholders are numbered beginning with pos.
+func (dsc DscType) keyWhereStr(pos int) string {
+	if len(dsc.key.nameList) > 0 {
+		var list []string
+		for j, nm := range dsc.key.nameList {
+			list = append(list, fmt.Sprintf("%s = %s", dsc.ident(nm), dsc.dialect.PlaceholderMark(pos+j)))
+		}
+		return strings.Join(list, " AND ")
+	}
+	return fmt.Sprintf("%s = %s", dsc.ident(dsc.primaryKeyColumn()), dsc.dialect.PlaceholderMark(pos))
+}
+
+// keyArg returns the arguments for the condition returned by keyWhereStr()
+// that identifies the record recVl.
+func (dsc DscType) keyArg(recVl reflect.Value) (argList []interface{}) {
+	if len(dsc.key.sfList) > 0 {
+		for _, sf := range dsc.key.sfList {
+			argList = append(argList, recVl.FieldByIndex(sf.Index).Interface())
+		}
+	} else {
+		argList = append(argList, recVl.FieldByIndex(dsc.idSf.Index).Interface())
+	}
+	return
+}
+
+// UpdateStr returns a command string suitable for updating records into
+// the table associated with the receiver. The record is identified by its
+// field tagged with db_primary or, if one or more "db" fields are also tagged
+// with db_primary, by the combination of those fields.
+func (dsc DscType) UpdateStr(fldNames ...string) string {
+	fldNames = dsc.updateNames(fldNames...)
+	var eqList strListType
+	for j, nm := range fldNames {
+		// fmt.Printf("sf.Name [%s], %v\n", sf.Name, fldMap[sf.Name])
+		eqList.appendf("%s = %s", dsc.ident(nm), dsc.dialect.PlaceholderMark(j+1))
+	}
+	return fmt.Sprintf("UPDATE %s SET %s WHERE %s;", dsc.tblIdentStr, eqList.join(),
+		dsc.keyWhereStr(len(fldNames)+1))
+}
+
+// UpdateArg returns a slice of interface values that can be expanded in an SQL
+// call. This function needs to be called once for each updated record. The
+// passed-in val can be a properly tagged structure variable or a pointer to
+// one. Additionally, the field names that are passed in must be the same ones,
+// in the same order, as passed to UpdateStr(). The record to update is
+// identified by the field tagged 'db_primary'. That field must contain the
+// same identifier as it had when retrieved from the database. If one or more
+// fields are tagged with both 'db' and 'db_primary', the record is instead
+// identified by the values of those fields.
+func (dsc DscType) UpdateArg(rec interface{}, fldNames ...string) (argList []interface{}, err error) {
+	err = dsc.writable()
+	if err != nil {
+		return
+	}
+	if dsc.idPresent || len(dsc.key.nameList) > 0 {
+		vl := reflect.ValueOf(rec)
+		if vl.Kind() == reflect.Ptr {
+			vl = vl.Elem()
+		}
+		if vl.Type() == dsc.recTp {
+			fldNames = dsc.updateNames(fldNames...)
+			tm := NowFunc()
+			// var list sfListType
+			var ok bool
+			var sf reflect.StructField
+			for _, nm := range fldNames {
+				// See InsertArg for correct way of doing this
+				if err == nil {
+					// fmt.Printf("sf.Name [%s], %v\n", sf.Name, fldMap[sf.Name])
+					sf, ok = dsc.nameMap[nm]
+					if ok {
+						var val interface{}
+						fldVl := vl.FieldByIndex(sf.Index)
+						if dsc.autoMap[nm] {
+							fldVl = autoTime(fldVl, tm)
+						}
+						val, err = dsc.storeValue(nm, fldVl)
+						argList = append(argList, val)
+						// list.append(sf)
+					} else {
+						err = fmt.Errorf("field name \"%s\" not in structure", nm)
+					}
+				}
+			}
+			if err == nil {
+				argList = append(argList, dsc.keyArg(vl)...)
+			}
+		} else {
+			err = fmt.Errorf("value passed into update must be a structure (or pointer to a structure) "+
+				"of type %s", dsc.recTp.String())
+		}
+	} else {
+		err = errors.New("update requires structure with primary ID")
+	}
+	return
+}
+
+// UpdateWhereArg returns a command string, and its arguments, suitable for
+// setting the columns named by the keys of setCols to the corresponding values
+// in all records of the table associated with the receiver that satisfy
+// tailStr, for example "WHERE status = ?". The columns are assigned in sorted
+// order, and the parameters of tailStr, passed in args, follow the values; with
+// a dialect that numbers its placeholders, those of tailStr begin after the
+// values. Each value must be nil, which stores NULL, or convertible to the type
+// of the field associated with its column (a number is not converted to a
+// string), and is stored as a value of the field would be. Fields tagged with
+// "db_autoupdate" are set to the current time unless they are named in
+// setCols. An error occurs if setCols is empty or names a column that is not
+// the "db" tag of a field.
+func (dsc DscType) UpdateWhereArg(setCols map[string]interface{}, tailStr string,
+	args ...interface{}) (cmdStr string, argList []interface{}, err error) {
+	err = dsc.writable()
+	if err == nil && len(setCols) == 0 {
+		err = errors.New("update requires at least one column")
+	}
+	if err != nil {
+		return
+	}
+	var nameList []string
+	for nameStr := range setCols {
+		nameList = append(nameList, nameStr)
+	}
+	sort.Strings(nameList)
+	nameList = dsc.updateNames(nameList...)
+	tm := NowFunc()
+	var eqList strListType
+	for j := 0; j < len(nameList) && err == nil; j++ {
+		nameStr := nameList[j]
+		sf, ok := dsc.nameMap[nameStr]
+		if ok {
+			var val interface{}
+			v, set := setCols[nameStr]
+			if !set {
+				// Timestamp maintained on update
+				val = autoTime(reflect.New(sf.Type).Elem(), tm).Interface()
+			} else if v != nil {
+				vl := reflect.ValueOf(v)
+				// Go converts integers to strings as code points, which is
+				// not wanted here
+				if vl.Type().ConvertibleTo(sf.Type) &&
+					(sf.Type.Kind() != reflect.String || vl.Kind() == reflect.String) {
+					val, err = dsc.storeValue(nameStr, vl.Convert(sf.Type))
+				} else {
+					err = fmt.Errorf("value of type %s cannot be stored in field \"%s\" of type %s",
+						vl.Type().String(), nameStr, sf.Type.String())
+				}
+			}
+			eqList.appendf("%s = %s", dsc.ident(nameStr), dsc.dialect.PlaceholderMark(j+1))
+			argList = append(argList, val)
+		} else {
+			err = fmt.Errorf("field name \"%s\" not in structure", nameStr)
+		}
+	}
+	if err == nil {
+		cmdStr = fmt.Sprintf("UPDATE %s SET %s%s;", dsc.tblIdentStr, eqList.join(), prePad(tailStr))
+		argList = append(argList, args...)
+	} else {
+		argList = nil
+	}
+	return
+}
+
+// CompareAndSwapStr returns a command string suitable for setting the column
+// nameStr of a record in the table associated with the receiver to a new value
+// only if it currently holds an expected old value. The record is identified
+// as in UpdateStr(). The arguments are returned by CompareAndSwapArg().
+func (dsc DscType) CompareAndSwapStr(nameStr string) string {
+	keyCount := 1
+	if len(dsc.key.nameList) > 0 {
+		keyCount = len(dsc.key.nameList)
+	}
+	identStr := dsc.ident(nameStr)
+	return fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s AND %s = %s;", dsc.tblIdentStr,
+		identStr, dsc.dialect.PlaceholderMark(1), dsc.keyWhereStr(2),
+		identStr, dsc.dialect.PlaceholderMark(keyCount+2))
+}
+
+// CompareAndSwapArg returns the arguments for the command returned by
+// CompareAndSwapStr() with the same nameStr. rec identifies the record as in
+// UpdateArg(); its other fields are not used. oldVal and newVal must be
+// convertible to the type of the field associated with nameStr. Both are
+// subject to the same validation and conversion as values written by
+// UpdateArg(), so that oldVal is compared with the form in which the field is
+// stored.
+func (dsc DscType) CompareAndSwapArg(rec interface{}, nameStr string,
+	oldVal, newVal interface{}) (argList []interface{}, err error) {
+	err = dsc.writable()
+	if err != nil {
+		return
+	}
+	if !dsc.idPresent && len(dsc.key.nameList) == 0 {
+		return nil, errors.New("compare-and-swap requires structure with primary ID")
+	}
+	sf, ok := dsc.nameMap[nameStr]
+	if !ok {
+		return nil, fmt.Errorf("field name \"%s\" not in structure", nameStr)
+	}
+	vl := reflect.ValueOf(rec)
+	if vl.Kind() == reflect.Ptr {
+		vl = vl.Elem()
+	}
+	if vl.Type() != dsc.recTp {
+		return nil, fmt.Errorf("value passed into compare-and-swap must be a structure "+
+			"(or pointer to a structure) of type %s", dsc.recTp.String())
+	}
+	var valList [2]reflect.Value
+	for j, val := range []interface{}{oldVal, newVal} {
+		valList[j] = reflect.ValueOf(val)
+		if !valList[j].IsValid() || !valList[j].Type().ConvertibleTo(sf.Type) {
+			return nil, fmt.Errorf("value %v cannot be converted to %s", val, sf.Type.String())
+		}
+		valList[j] = valList[j].Convert(sf.Type)
+	}
+	var val interface{}
+	val, err = dsc.storeValue(nameStr, valList[1])
+	if err == nil {
+		argList = append(append(argList, val), dsc.keyArg(vl)...)
+		// The old value is compared with the stored form of the field
+		val, err = dsc.storeValue(nameStr, valList[0])
+		argList = append(argList, val)
+	}
+	if err != nil {
+		argList = nil
+	}
+	return
+}
+
+// InsertStr returns a command string suitable for inserting new records into
+// the table associated with the receiver.
+func (dsc DscType) InsertStr() string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)%s;",
+		dsc.tblIdentStr, dsc.insert.nameStr, dsc.insert.qmStr, prePad(dsc.returningStr()))
+}
+
+// InsertReturningStr is like InsertStr() except that the command returns, in
+// a single row, the assigned identifier (if the record structure has an ID
+// field) followed by the values of the cols columns of the inserted record.
+// This captures values assigned by the database, for example column defaults.
+// The RETURNING clause requires PostgreSQL or SQLite 3.35 or later.
+func (dsc DscType) InsertReturningStr(cols []string) string {
+	var list []string
+	if dsc.idPresent {
+		list = append(list, dsc.ident(dsc.primaryKeyColumn()))
+	}
+	list = append(list, dsc.identList(cols)...)
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s;",
+		dsc.tblIdentStr, dsc.insert.nameStr, dsc.insert.qmStr, strings.Join(list, ", "))
 }
 
 // InsertOrReplaceStr returns a command string suitable for inserting (or
 // replacing, if the insertion would violate a unique constraint) records into
 // the table associated with the receiver.
 func (dsc DscType) InsertOrReplaceStr() string {
-	return fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s);",
-		dsc.tblStr, dsc.insert.nameStr, dsc.insert.qmStr)
+	return fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s)%s;",
+		dsc.tblIdentStr, dsc.insert.nameStr, dsc.insert.qmStr, prePad(dsc.returningStr()))
+}
+
+// InsertOrIgnoreStr returns a command string suitable for inserting records
+// into the table associated with the receiver. A record whose insertion would
+// violate a unique constraint is skipped without error. The command is built
+// with Dialect.IgnoreClause().
+func (dsc DscType) InsertOrIgnoreStr() string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) %s%s;",
+		dsc.tblIdentStr, dsc.insert.nameStr, dsc.insert.qmStr, dsc.dialect.IgnoreClause(),
+		prePad(dsc.returningStr()))
+}
+
+// upsertNames returns the names of the columns that an upsert modifies when
+// the insertion conflicts with an existing record. If updateCols is empty, all
+// columns other than those in conflictCols are modified.
+func (dsc DscType) upsertNames(conflictCols, updateCols []string) []string {
+	if len(updateCols) == 0 {
+		for _, nameStr := range dsc.insert.nameList {
+			found := false
+			for _, str := range conflictCols {
+				found = found || str == nameStr
+			}
+			if !found {
+				updateCols = append(updateCols, nameStr)
+			}
+		}
+	}
+	return updateCols
+}
+
+// UpsertStr returns a command string suitable for inserting records into the
+// table associated with the receiver or, if a record with the same values in
+// the conflictCols columns already exists, updating the updateCols columns of
+// that record in place. Unlike InsertOrReplaceStr(), the existing record keeps
+// its identifier and the values of the columns that are not updated. If
+// updateCols is empty, all columns other than conflictCols are updated. The
+// columns in conflictCols must be covered by a unique index or constraint. If
+// the record structure has an ID field, the command returns the identifier of
+// the inserted or updated record. Upserts require SQLite 3.35 or later.
+func (dsc DscType) UpsertStr(conflictCols []string, updateCols []string) string {
+	var retStr string
+	if dsc.idPresent {
+		retStr = " RETURNING " + dsc.ident(dsc.primaryKeyColumn())
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) %s%s;",
+		dsc.tblIdentStr, dsc.insert.nameStr, dsc.insert.qmStr,
+		dsc.conflictStr(conflictCols, updateCols), retStr)
+}
+
+// conflictStr returns the clause of an upsert command that specifies the
+// update of conflicting records.
+func (dsc DscType) conflictStr(conflictCols, updateCols []string) string {
+	return dsc.dialect.UpsertClause(dsc.identList(conflictCols),
+		dsc.identList(dsc.upsertNames(conflictCols, updateCols)))
+}
+
+// UpsertMultiStr is like UpsertStr() except that the command inserts or
+// updates rowCount records, whose arguments are expanded one record after
+// another as in InsertMultiStr(). No identifiers are returned.
+func (dsc DscType) UpsertMultiStr(rowCount int, conflictCols []string, updateCols []string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s %s;",
+		dsc.tblIdentStr, dsc.insert.nameStr, dsc.valuesStr(rowCount),
+		dsc.conflictStr(conflictCols, updateCols))
+}
+
+// uniqueCols returns true if the columns in cols, in any order, are exactly
+// those of the receiver's composite key or of one of its unique indexes.
+func (dsc DscType) uniqueCols(cols []string) bool {
+	same := func(list []string) bool {
+		if len(list) != len(cols) {
+			return false
+		}
+		for _, str := range cols {
+			found := false
+			for _, nameStr := range list {
+				found = found || nameStr == str
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	if same(dsc.key.nameList) {
+		return true
+	}
+	for _, idxList := range dsc.create.uniqueMap {
+		var list []string
+		for _, idx := range idxList {
+			list = append(list, idx.fldStr)
+		}
+		if same(list) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpsertArg returns the arguments for the command returned by UpsertStr()
+// with the same conflictCols and updateCols. They are the same as those
+// returned by InsertArg(). An error occurs if conflictCols is empty or if a
+// column in conflictCols or updateCols is not the "db" tag of a field in the
+// record structure.
+func (dsc DscType) UpsertArg(rec interface{}, conflictCols []string,
+	updateCols []string) (argList []interface{}, setID func(int64), err error) {
+	if len(conflictCols) == 0 {
+		err = errors.New("upsert requires at least one conflict column")
+	}
+	for _, nameStr := range append(append([]string{}, conflictCols...), updateCols...) {
+		if err == nil {
+			if _, ok := dsc.nameMap[nameStr]; !ok {
+				err = fmt.Errorf("field name \"%s\" not in structure", nameStr)
+			}
+		}
+	}
+	if err == nil {
+		if len(dsc.upsertNames(conflictCols, updateCols)) == 0 {
+			err = errors.New("upsert requires at least one column to update")
+		} else {
+			argList, setID, err = dsc.InsertArg(rec)
+		}
+	}
+	return
+}
+
+// InsertMultiStr returns a command string suitable for inserting rowCount new
+// records into the table associated with the receiver with a single
+// statement. The arguments for each record, as returned by InsertArg(), are
+// expanded one record after another.
+func (dsc DscType) InsertMultiStr(rowCount int) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s%s;",
+		dsc.tblIdentStr, dsc.insert.nameStr, dsc.valuesStr(rowCount), prePad(dsc.returningStr()))
+}
+
+// valuesStr returns the placeholder lists of a multi-row insertion of
+// rowCount records, for example "(?, ?), (?, ?)".
+func (dsc DscType) valuesStr(rowCount int) string {
+	var rowList, qmList strListType
+	fldCount := len(dsc.insert.nameList)
+	for j := 0; j < rowCount; j++ {
+		qmList = qmList[:0]
+		for k := 0; k < fldCount; k++ {
+			qmList.append(dsc.dialect.PlaceholderMark(j*fldCount + k + 1))
+		}
+		rowList.appendf("(%s)", qmList.join())
+	}
+	return rowList.join()
+}
+
+// storeValue returns the value of the field fldVl, associated with the column
+// nameStr, in the form in which it is passed to the database. An error is
+// returned if the value is not permitted in the column.
+func (dsc DscType) storeValue(nameStr string, fldVl reflect.Value) (val interface{}, err error) {
+	if dsc.jsonMap[nameStr] {
+		var data []byte
+		data, err = json.Marshal(fldVl.Interface())
+		if err == nil {
+			val = string(data)
+		} else {
+			err = fmt.Errorf("cannot store field \"%s\" as JSON: %s", nameStr, err)
+		}
+		return
+	}
+	lower, trim := dsc.trimMap[nameStr]
+	if trim {
+		str := strings.TrimSpace(fldVl.String())
+		if lower {
+			str = strings.ToLower(str)
+		}
+		fldVl = reflect.ValueOf(str).Convert(fldVl.Type())
+	}
+	val = fldVl.Interface()
+	if nameStr == dsc.soft.nameStr && fldVl.Type() == glTimeTp && fldVl.Interface().(time.Time).IsZero() {
+		// A record that has not been soft-deleted has a NULL timestamp
+		val = nil
+	}
+	switch fldVl.Kind() {
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		if fldVl.Uint() > math.MaxInt64 {
+			// Stored integers are signed
+			return nil, fmt.Errorf("value %d of field \"%s\" exceeds the largest integer "+
+				"that can be stored, %d", fldVl.Uint(), nameStr, int64(math.MaxInt64))
+		}
+	}
+	valMap, ok := dsc.enumMap[nameStr]
+	if ok && !valMap[val] {
+		err = fmt.Errorf("value %v is not a registered enumeration value of field \"%s\"",
+			val, nameStr)
+	}
+	if zero, ok := dsc.zeroMap[nameStr]; ok && err == nil && fldVl.IsZero() {
+		// The column holds a sentinel in place of the zero value
+		val = zero
+	} else if fldVl.Kind() == reflect.Bool {
+		// Bool fields have integer columns, to which some drivers, for
+		// example those of PostgreSQL, do not bind a bool
+		val = int64(0)
+		if fldVl.Bool() {
+			val = int64(1)
+		}
+	}
+	return
+}
+
+// NowFunc returns the current time for fields tagged with "db_autocreate" and
+// "db_autoupdate". It can be replaced, for example in tests, to control the
+// recorded times.
+var NowFunc = time.Now
+
+// autoTime returns the value of the timestamp field fldVl set to tm. The field
+// itself is also set if it is addressable, that is, if the record was passed
+// by pointer.
+func autoTime(fldVl reflect.Value, tm time.Time) reflect.Value {
+	tmVl := reflect.ValueOf(tm)
+	if fldVl.Kind() == reflect.Ptr {
+		tmVl = reflect.ValueOf(&tm)
+	}
+	if fldVl.CanSet() {
+		fldVl.Set(tmVl)
+	}
+	return tmVl
+}
+
+// WithEnum returns a copy of the receiver in which the field associated with
+// the column nameStr is restricted to the values in valList. Each value must
+// be convertible to the type of the field. Subsequent calls to InsertArg()
+// and UpdateArg() with the returned descriptor, and consequently the
+// corresponding WrapType methods, fail with an error before reaching the
+// database if the field holds any other value. The receiver itself is not
+// modified.
+func (dsc DscType) WithEnum(nameStr string, valList ...interface{}) (DscType, error) {
+	var err error
+	sf, ok := dsc.nameMap[nameStr]
+	if ok {
+		valMap := make(map[interface{}]bool, len(valList))
+		for _, val := range valList {
+			if err == nil {
+				vl := reflect.ValueOf(val)
+				if vl.IsValid() && vl.Type().ConvertibleTo(sf.Type) {
+					valMap[vl.Convert(sf.Type).Interface()] = true
+				} else {
+					err = fmt.Errorf("enumeration value %v cannot be converted to %s",
+						val, sf.Type.String())
+				}
+			}
+		}
+		if err == nil {
+			enumMap := make(map[string]map[interface{}]bool, len(dsc.enumMap)+1)
+			for k, v := range dsc.enumMap {
+				enumMap[k] = v
+			}
+			enumMap[nameStr] = valMap
+			dsc.enumMap = enumMap
+		}
+	} else {
+		err = fmt.Errorf("field name \"%s\" not in structure", nameStr)
+	}
+	return dsc, err
+}
+
+// insertNames returns the columns, and the associated fields, that are set
+// by an insertion of the cols columns. If cols is empty, all columns are
+// returned. Otherwise, fields tagged with "db_autocreate" or "db_autoupdate"
+// are included even if they are not named in cols.
+func (dsc DscType) insertNames(cols []string) (nameList []string, sfList sfListType, err error) {
+	if len(cols) == 0 {
+		return dsc.insert.nameList, dsc.insert.sfList, nil
+	}
+	have := make(map[string]bool, len(cols))
+	for _, nameStr := range cols {
+		sf, ok := dsc.nameMap[nameStr]
+		if !ok {
+			return nil, nil, fmt.Errorf("field name \"%s\" not in structure", nameStr)
+		}
+		if have[nameStr] {
+			return nil, nil, fmt.Errorf("column \"%s\" named more than once", nameStr)
+		}
+		have[nameStr] = true
+		nameList = append(nameList, nameStr)
+		sfList.append(sf)
+	}
+	// Timestamps maintained on insertion are always included
+	for j, nameStr := range dsc.insert.nameList {
+		if _, ok := dsc.autoMap[nameStr]; ok && !have[nameStr] {
+			nameList = append(nameList, nameStr)
+			sfList.append(dsc.insert.sfList[j])
+		}
+	}
+	return
 }
 
 // InsertArg returns a slice of interface values that can be expanded in an SQL
@@ -384,17 +1932,65 @@ func (dsc DscType) InsertOrReplaceStr() string {
 // method also returns a function that can be called to set the record's ID
 // field.
 func (dsc DscType) InsertArg(rec interface{}) (argList []interface{}, setID func(int64), err error) {
+	return dsc.InsertColsArg(rec)
+}
+
+// InsertColsStr is like InsertStr() except that only the cols columns, and
+// the timestamp columns maintained by this package, are set. The database
+// assigns the other columns their default values. If cols is empty, all
+// columns are set. The arguments are returned by InsertColsArg() with the same
+// cols.
+func (dsc DscType) InsertColsStr(cols ...string) string {
+	nameList, _, _ := dsc.insertNames(cols)
+	if nameList == nil {
+		// Unknown column; InsertColsArg() reports the error
+		nameList = cols
+	}
+	var qmList strListType
+	for j := range nameList {
+		qmList.append(dsc.dialect.PlaceholderMark(j + 1))
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)%s;", dsc.tblIdentStr,
+		strings.Join(dsc.identList(nameList), ", "), qmList.join(), prePad(dsc.returningStr()))
+}
+
+// InsertColsArg is like InsertArg() except that only the values of the fields
+// associated with the cols columns, and with the timestamp columns maintained
+// by this package, are returned. An error occurs if a column in cols is not the
+// "db" tag of an inserted field or is named more than once.
+func (dsc DscType) InsertColsArg(rec interface{}, cols ...string) (argList []interface{},
+	setID func(int64), err error) {
+	err = dsc.writable()
+	if err != nil {
+		return
+	}
+	var nameList []string
+	var sfList sfListType
+	nameList, sfList, err = dsc.insertNames(cols)
+	if err != nil {
+		return
+	}
 	vl := reflect.ValueOf(rec)
 	isPtr := vl.Kind() == reflect.Ptr
 	if isPtr {
 		vl = vl.Elem()
 	}
 	if vl.Type() == dsc.recTp {
-		var sf reflect.StructField
-		for _, sf = range dsc.insert.sfList {
-			argList = append(argList, vl.FieldByIndex(sf.Index).Interface())
+		var val interface{}
+		tm := NowFunc()
+		for j, sf := range sfList {
+			if err == nil {
+				fldVl := vl.FieldByIndex(sf.Index)
+				if _, ok := dsc.autoMap[nameList[j]]; ok {
+					fldVl = autoTime(fldVl, tm)
+				}
+				val, err = dsc.storeValue(nameList[j], fldVl)
+				argList = append(argList, val)
+			}
 		}
-		if dsc.idPresent && isPtr {
+		if err != nil {
+			argList = nil
+		} else if dsc.idPresent && isPtr {
 			vl = vl.FieldByIndex(dsc.idSf.Index)
 			if vl.CanSet() {
 				setID = func(id int64) {
@@ -410,10 +2006,82 @@ func (dsc DscType) InsertArg(rec interface{}) (argList []interface{}, setID func
 	return
 }
 
+// DeleteStr returns a command string suitable for removing the records that
+// satisfy tailStr from the table associated with the receiver. If tailStr is
+// empty, all records are removed.
+func (dsc DscType) DeleteStr(tailStr string) string {
+	return fmt.Sprintf("DELETE FROM %s%s;", dsc.tblIdentStr, prePad(tailStr))
+}
+
+// DeleteRecArg returns the command string and arguments that remove, or mark
+// as deleted as described in SoftDeleteArg(), the stored record identified by
+// rec. rec can be a properly tagged structure variable or a pointer to one.
+// The record is identified as in UpdateArg(); its other fields are not used.
+// An error occurs if the record structure has no primary key.
+func (dsc DscType) DeleteRecArg(rec interface{}) (cmdStr string, argList []interface{}, err error) {
+	err = dsc.writable()
+	if err == nil && !dsc.idPresent && len(dsc.key.nameList) == 0 {
+		err = errors.New("deletion of a record requires structure with primary ID")
+	}
+	if err == nil {
+		vl := reflect.ValueOf(rec)
+		if vl.Kind() == reflect.Ptr {
+			vl = vl.Elem()
+		}
+		if vl.Type() == dsc.recTp {
+			cmdStr, argList = dsc.SoftDeleteArg("WHERE "+dsc.keyWhereStr(1), dsc.keyArg(vl)...)
+		} else {
+			err = fmt.Errorf("value passed into delete must be a structure (or pointer to a structure) "+
+				"of type %s", dsc.recTp.String())
+		}
+	}
+	return
+}
+
+// BuildInsert returns the command string and arguments that WrapType.Insert()
+// executes for rec. No database access takes place.
+func (dsc DscType) BuildInsert(rec interface{}) (cmdStr string, args []interface{}, err error) {
+	args, _, err = dsc.InsertArg(rec)
+	if err == nil {
+		cmdStr = dsc.InsertStr()
+	}
+	return
+}
+
+// BuildUpdate returns the command string and arguments that WrapType.Update()
+// executes for rec and fldNames. No database access takes place.
+func (dsc DscType) BuildUpdate(rec interface{}, fldNames ...string) (cmdStr string, args []interface{}, err error) {
+	args, err = dsc.UpdateArg(rec, fldNames...)
+	if err == nil {
+		cmdStr = dsc.UpdateStr(fldNames...)
+	}
+	return
+}
+
+// BuildDelete returns the command string and arguments that WrapType.Delete()
+// executes for tailStr and args. No database access takes place.
+func (dsc DscType) BuildDelete(tailStr string, args ...interface{}) (cmdStr string, argList []interface{}, err error) {
+	cmdStr, argList = dsc.SoftDeleteArg(tailStr, args...)
+	return
+}
+
+// BuildSelect returns the command string and arguments that WrapType.Query()
+// and WrapType.QueryRow() submit for recPtr, tailStr and args. recPtr is
+// validated in the same way those methods validate it. No database access
+// takes place.
+func (dsc DscType) BuildSelect(recPtr interface{}, tailStr string, args ...interface{}) (cmdStr string, argList []interface{}, err error) {
+	_, err = dsc.SelectArg(recPtr)
+	if err == nil {
+		cmdStr = dsc.SelectStr(tailStr)
+		argList = args
+	}
+	return
+}
+
 // TruncateStr returns a command string that will remove all records from the
 // table associated with the receiver.
 func (dsc DscType) TruncateStr() string {
-	return fmt.Sprintf("DELETE FROM %s;", dsc.tblStr)
+	return fmt.Sprintf("DELETE FROM %s;", dsc.tblIdentStr)
 }
 
 // Describe generates a descriptor containing meta information of the passed-in
@@ -421,12 +2089,51 @@ func (dsc DscType) TruncateStr() string {
 // error occurs if the record stucture fails to meet the tag requirements as
 // explained in the documentation.
 func Describe(rec interface{}) (dsc DscType, err error) {
-	vl := reflect.ValueOf(rec)
-	kd := vl.Kind()
-	if kd == reflect.Ptr {
-		vl = vl.Elem()
+	return DescribeType(reflect.TypeOf(rec))
+}
+
+// DescribeType is like Describe() except that the record structure is
+// specified by its type, or the type of a pointer to it, rather than by a
+// value. This suits code, for example a code generator, that holds a
+// reflect.Type and would otherwise need to construct a value of the type. An
+// error occurs if tp is not a structure type.
+func DescribeType(tp reflect.Type) (dsc DscType, err error) {
+	if tp != nil && tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	return describe(tp)
+}
+
+// glDscCache holds the descriptors generated by DescribeCached(), keyed by
+// record type.
+var glDscCache = struct {
+	sync.RWMutex
+	mp map[reflect.Type]DscType
+}{mp: make(map[reflect.Type]DscType)}
+
+// DescribeCached is like Describe() except that the descriptor of each record
+// type is generated only once and then shared by subsequent calls, including
+// those made by other packages. This is safe because a descriptor is not
+// modified after it is generated; methods such as WithDialect() return
+// modified copies. Errors are not cached. DescribeCached is safe for
+// concurrent use by goroutines.
+func DescribeCached(rec interface{}) (dsc DscType, err error) {
+	tp := reflect.TypeOf(rec)
+	if tp != nil && tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	var ok bool
+	glDscCache.RLock()
+	dsc, ok = glDscCache.mp[tp]
+	glDscCache.RUnlock()
+	if !ok {
+		dsc, err = Describe(rec)
+		if err == nil {
+			glDscCache.Lock()
+			glDscCache.mp[tp] = dsc
+			glDscCache.Unlock()
+		}
 	}
-	dsc, err = describe(vl.Type())
 	return
 }
 
@@ -440,6 +2147,91 @@ func MustDescribe(rec interface{}) (dsc DscType) {
 	return
 }
 
+// TableName returns the name of the database table associated with the
+// receiver.
+func (dsc DscType) TableName() string {
+	return dsc.tblStr
+}
+
+// Columns returns the names of the columns associated with the fields that
+// have a "db" tag, in structure order. The primary key column is not included;
+// see PrimaryKey(). The returned slice is a copy and may be modified by the
+// caller.
+func (dsc DscType) Columns() (list []string) {
+	if dsc.colList != nil {
+		for _, col := range dsc.colList {
+			list = append(list, col.Name)
+		}
+	} else {
+		list = append(list, dsc.insert.nameList...)
+	}
+	return
+}
+
+// PrimaryKey returns the name of the column that holds the field tagged with
+// "db_primary" (and no "db" tag), for example "rowid" for SQLite unless the
+// tag names another column. The returned
+// boolean is false if the record structure has no such field.
+func (dsc DscType) PrimaryKey() (nameStr string, ok bool) {
+	if dsc.idPresent {
+		nameStr, ok = dsc.primaryKeyColumn(), true
+	}
+	return
+}
+
+// Schema returns a human-readable summary of the receiver for use when
+// verifying the interpretation of structure tags. The first line names the
+// table. Each column follows on its own line with its declaration, as in the
+// CREATE TABLE command, and the path and type of its field. The composite
+// primary key, foreign keys and indexes, sorted by name, follow the columns.
+func (dsc DscType) Schema() string {
+	var b strings.Builder
+	b.WriteString("table ")
+	b.WriteString(dsc.tblIdentStr)
+	if dsc.colList != nil {
+		b.WriteString(" (read-only)")
+		for _, col := range dsc.colList {
+			fmt.Fprintf(&b, "\n  %s [%s]", dsc.ident(col.Name), col.Kind)
+		}
+		return b.String()
+	}
+	if dsc.idPresent {
+		fmt.Fprintf(&b, "\n  %s primary key [%s %s]", dsc.ident(dsc.primaryKeyColumn()),
+			fieldPath(dsc.recTp, dsc.idSf.Index), dsc.idSf.Type)
+	}
+	for j, sf := range dsc.insert.sfList {
+		fmt.Fprintf(&b, "\n  %s [%s %s]", dsc.create.colDefList[j],
+			fieldPath(dsc.recTp, sf.Index), sf.Type)
+	}
+	if len(dsc.key.nameList) > 0 {
+		fmt.Fprintf(&b, "\n  primary key (%s)", strings.Join(dsc.identList(dsc.key.nameList), ", "))
+	}
+	for _, fk := range dsc.create.fkList {
+		fmt.Fprintf(&b, "\n  foreign key (%s) references %s (%s)",
+			dsc.ident(fk.nameStr), dsc.ident(fk.tblStr), dsc.ident(fk.colStr))
+		if fk.cascade {
+			b.WriteString(" on delete cascade")
+		}
+	}
+	idxCount := len(dsc.create.idxMap) + len(dsc.create.uniqueMap)
+	idxStrMap := make(map[string]string, idxCount)
+	idxNameList := make([]string, 0, idxCount)
+	for k, v := range dsc.create.idxMap {
+		idxStrMap[k] = dsc.indexStr("index", k, v)
+		idxNameList = append(idxNameList, k)
+	}
+	for k, v := range dsc.create.uniqueMap {
+		idxStrMap[k] = dsc.indexStr("unique index", k, v)
+		idxNameList = append(idxNameList, k)
+	}
+	sort.Strings(idxNameList)
+	for _, k := range idxNameList {
+		b.WriteString("\n  ")
+		b.WriteString(idxStrMap[k])
+	}
+	return b.String()
+}
+
 // String satisfies the fmt.Stringer interface and returns the library name
 func (dsc *DscType) String() string {
 	return "dbmap"
@@ -0,0 +1,144 @@
+//go:build postgres
+// +build postgres
+
+package dbmap_test
+
+import (
+	"fmt"
+	"github.com/jung-kurt/dbmap"
+	"strings"
+	"time"
+)
+
+// This example demonstrates the generation of commands for PostgreSQL from
+// the same record type that is used with SQLite in the other examples.
+func ExampleDscType_WithDialect() {
+	dsc := glRecDsc.WithDialect(dbmap.PostgresDialect{})
+	createStr, _ := dsc.CreateStr()
+	fmt.Println(createStr)
+	fmt.Println(dsc.InsertStr())
+	fmt.Println(dsc.InsertMultiStr(2))
+	fmt.Println(dsc.UpdateStr("str", "num"))
+	fmt.Println(dsc.SelectStr("WHERE num > $1"))
+	fmt.Println(glRecDsc.UpdateStr("str", "num"))
+	// Output:
+	// CREATE TABLE rec (id BIGSERIAL PRIMARY KEY, str text, num bigint);
+	// INSERT INTO rec (str, num) VALUES ($1, $2) RETURNING id;
+	// INSERT INTO rec (str, num) VALUES ($1, $2), ($3, $4) RETURNING id;
+	// UPDATE rec SET str = $1, num = $2 WHERE id = $3;
+	// SELECT id, str, num FROM rec WHERE num > $1;
+	// UPDATE rec SET str = ?, num = ? WHERE rowid = ?;
+}
+
+// This example demonstrates the validation of conditions written with
+// numbered placeholders. The numbers refer to the combined parameters of the
+// active conditions.
+func ExampleDscType_WhereAll() {
+	type recType struct {
+		ID  int64  `db_primary:"*" db_table:"rec"`
+		Str string `db:"str"`
+		Num int64  `db:"num"`
+	}
+	dsc := dbmap.MustDescribe(recType{}).WithDialect(dbmap.PostgresDialect{})
+	show := func(conds ...dbmap.Cond) {
+		tailStr, args, err := dsc.WhereAll(conds...)
+		if err == nil {
+			fmt.Printf("[%s] %v\n", tailStr, args)
+		} else {
+			fmt.Println(err)
+		}
+	}
+	show(dbmap.Cond{ExprStr: "num >= $1", Args: []interface{}{5}},
+		dbmap.Cond{ExprStr: "str = $2", Args: []interface{}{"ab"}})
+	show(dbmap.Cond{ExprStr: "num >= $1", Args: []interface{}{5}},
+		dbmap.Cond{ExprStr: "str = $1", Args: []interface{}{"ab"}})
+	show(dbmap.Cond{ExprStr: "num = $10", Args: []interface{}{5}})
+	show(dbmap.Cond{ExprStr: "num BETWEEN $1 AND $2", Args: []interface{}{5}})
+	// Output:
+	// [WHERE (num >= $1) AND (str = $2)] [5 ab]
+	// clause "WHERE (num >= $1) AND (str = $1)" has no placeholder $2 for parameter 2
+	// clause "WHERE num = $10" has no placeholder $1 for parameter 1
+	// clause "WHERE num BETWEEN $1 AND $2" has placeholder $2 but 1 parameters
+}
+
+// This example demonstrates the commands with which the schema version is
+// maintained in a PostgreSQL database, which has no user_version pragma.
+func ExamplePostgresDialect_SetSchemaVersionStr() {
+	var d dbmap.PostgresDialect
+	fmt.Println(strings.Join(d.SetSchemaVersionStr(3), "\n"))
+	fmt.Println(strings.Join(d.SchemaVersionStr(), "\n"))
+	// Output:
+	// CREATE TABLE IF NOT EXISTS dbmap_schema (version integer NOT NULL);
+	// DELETE FROM dbmap_schema;
+	// INSERT INTO dbmap_schema (version) VALUES (3);
+	// CREATE TABLE IF NOT EXISTS dbmap_schema (version integer NOT NULL);
+	// SELECT COALESCE(MAX(version), 0) FROM dbmap_schema;
+}
+
+// This example demonstrates the commands for soft deletion in PostgreSQL, in
+// which the time of deletion follows the arguments of the WHERE clause.
+func ExampleDscType_SoftDeleteArg() {
+	type noteType struct {
+		ID      int64     `db_primary:"*" db_table:"note"`
+		Text    string    `db:"text"`
+		Deleted time.Time `db:"deleted" db_softdelete:"*"`
+	}
+	dsc := dbmap.MustDescribe(noteType{}).WithDialect(dbmap.PostgresDialect{})
+	cmdStr, args := dsc.SoftDeleteArg("WHERE text = $1", "drop")
+	fmt.Println(cmdStr, len(args))
+	fmt.Println(dsc.SelectStr("WHERE text = $1"))
+	// Output:
+	// UPDATE note SET deleted = $2 WHERE text = $1; 2
+	// SELECT id, text, deleted FROM (SELECT * FROM note WHERE deleted IS NULL) AS note WHERE text = $1;
+}
+
+// This example demonstrates the translation of SQLite collation names for
+// PostgreSQL. NOCASE has no built-in equivalent and is omitted.
+func ExamplePostgresDialect_CollateClause() {
+	type personType struct {
+		ID   int64  `db_primary:"*" db_table:"person"`
+		Name string `db:"name" db_collate:"NOCASE"`
+		Code string `db:"code" db_collate:"BINARY" db_index:"code1 en_US"`
+	}
+	createStr, idxList := dbmap.MustDescribe(personType{}).WithDialect(dbmap.PostgresDialect{}).CreateStr()
+	fmt.Println(createStr)
+	fmt.Println(strings.Join(idxList, "\n"))
+	// Output:
+	// CREATE TABLE person (id BIGSERIAL PRIMARY KEY, name text, code text COLLATE "C");
+	// CREATE INDEX person_code ON person (code COLLATE "en_US")
+}
+
+// This example demonstrates the arguments bound for a bool field, which
+// PostgreSQL stores in a bigint column. The value is passed as an integer
+// since PostgreSQL drivers do not bind a bool to such a column.
+func ExamplePostgresDialect_BoolLiteral() {
+	type taskType struct {
+		ID   int64  `db_primary:"*" db_table:"task"`
+		Name string `db:"name"`
+		Done bool   `db:"done"`
+	}
+	dsc := dbmap.MustDescribe(taskType{}).WithDialect(dbmap.PostgresDialect{})
+	createStr, _ := dsc.CreateStr()
+	fmt.Println(createStr)
+	args, _, err := dsc.InsertArg(&taskType{Name: "dishes", Done: true})
+	fmt.Printf("%v %T %v\n", args, args[1], err)
+	fmt.Println(dbmap.PostgresDialect{}.BoolLiteral(true))
+	// Output:
+	// CREATE TABLE task (id BIGSERIAL PRIMARY KEY, name text, done bigint);
+	// [dishes 1] int64 <nil>
+	// 1
+}
+
+// This example demonstrates the command that inserts a record unless it
+// duplicates the unique key of an existing record. A skipped record returns
+// no identifier.
+func ExamplePostgresDialect_IgnoreClause() {
+	type memberType struct {
+		ID    int64  `db_primary:"*" db_table:"member"`
+		Email string `db:"email" db_unique:"*"`
+	}
+	dsc := dbmap.MustDescribe(memberType{}).WithDialect(dbmap.PostgresDialect{})
+	fmt.Println(dsc.InsertOrIgnoreStr())
+	// Output:
+	// INSERT INTO member (email) VALUES ($1) ON CONFLICT DO NOTHING RETURNING id;
+}
@@ -2,13 +2,22 @@ package dbmap_test
 
 import (
 	_ "code.google.com/p/go-sqlite/go1/sqlite3"
+	"context"
 	"crypto/md5"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/jung-kurt/dbmap"
+	"math"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"testing"
+	"time"
 )
 
 const dbFileStr = "data/example.db"
@@ -30,6 +39,32 @@ func hashStr(v int64) string {
 	return base64.StdEncoding.EncodeToString(data[:])
 }
 
+// benchOpen returns a handle to a freshly populated database for use by
+// benchmarks.
+func benchOpen(b *testing.B, count int64) (hnd *sql.DB) {
+	var err error
+	dbFileStr := "data/bench.db"
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var rec recType
+		var j int64
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j = 0; j < count; j++ {
+			rec.Num = j
+			rec.Str = hashStr(j)
+			db.BufferInsert(&rec)
+		}
+		db.Flush()
+		err = db.Err()
+	}
+	if err != nil {
+		b.Fatal(err)
+	}
+	return
+}
+
 // This example demonstrates a simple use of dbmap. In typical scenarios, the
 // record type would be defined, and MustDescribe() called, outside of the
 // function in which database operations would be performed.
@@ -395,7 +430,7 @@ func ExampleDscType_05() {
 	// e multiple occurrence of "db_primary" tag
 	// f multiple occurrence of "db_table" tag
 	// g specified address must be of structure with one or more fields that have a "db" tag
-	// h malformed index tag: b
+	// h index sequence must be an integer: b
 	// dbmap, dbmap/wrap, true, true
 	// true
 	// nested transactions not supported
@@ -404,3 +439,4083 @@ func ExampleDscType_05() {
 	// passed-in value must be a structure pointer
 	// passed in record (dbmap_test.aType) for select does not match descriptor (dbmap_test.recType)
 }
+
+// This example demonstrates buffered insertion. Records are accumulated in
+// memory and written to the database in batches, either automatically when
+// the buffer is full or explicitly with a call to Flush(). Identifiers are
+// assigned to buffered records only when they are flushed.
+func ExampleDscType_06() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var list [6]recType
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.SetBufferSize(4)
+		for j := range list {
+			list[j].Num = int64(j + 1)
+			list[j].Str = hashStr(list[j].Num)
+			db.BufferInsert(&list[j])
+		}
+		for _, rec := range list {
+			fmt.Printf("Before flush [%d][%d]\n", rec.ID, rec.Num)
+		}
+		db.Flush()
+		var rec recType
+		db.Query(&rec, "WHERE num > ? ORDER BY num", 3)
+		for db.Next() {
+			fmt.Printf("Stored [%d][%d][%s]\n", rec.ID, rec.Num, list[rec.ID-1].Str)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// Before flush [1][1]
+	// Before flush [2][2]
+	// Before flush [3][3]
+	// Before flush [4][4]
+	// Before flush [0][5]
+	// Before flush [0][6]
+	// Stored [4][4][vI7K3ZJN1CdyIyjA4VmgeA==]
+	// Stored [5][5][cAF/r2v2X1QKpPk0OmVoVg==]
+	// Stored [6][6][zFuguoHOJcRHT8TRm/e4QQ==]
+}
+
+// This example demonstrates retrieving records into a slice with
+// QueryReuse(). The slice's backing storage is reused by subsequent queries,
+// so its previous contents are overwritten.
+func ExampleDscType_07() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var rec recType
+		var list []recType
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for _, rec.Str = range []string{"alpha", "bravo", "charlie", "delta"} {
+			rec.Num++
+			db.Insert(&rec)
+		}
+		db.QueryReuse(&list, "WHERE num < ? ORDER BY num", 4)
+		first := list
+		fmt.Println(len(list), list[0].Str, list[2].Str)
+		db.QueryReuse(&list, "WHERE num > ? ORDER BY num", 2)
+		fmt.Println(len(list), list[0].Str, list[1].Str, first[0].Str)
+		db.QueryReuse(&list, "WHERE num > ?", 10)
+		fmt.Println(len(list), cap(list) >= 3)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 3 alpha charlie
+	// 2 charlie delta charlie
+	// 0 true
+}
+
+// BenchmarkQueryReuse measures the retrieval of records into a reused slice.
+func BenchmarkQueryReuse(b *testing.B) {
+	hnd := benchOpen(b, 1000)
+	db := glRecDsc.Wrap(hnd)
+	var list []recType
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		db.QueryReuse(&list, "")
+	}
+	b.StopTimer()
+	if !db.OK() {
+		b.Fatal(db.Err())
+	}
+	hnd.Close()
+}
+
+// BenchmarkQueryNext measures the retrieval of records into a new slice with
+// a Query() and Next() loop.
+func BenchmarkQueryNext(b *testing.B) {
+	hnd := benchOpen(b, 1000)
+	db := glRecDsc.Wrap(hnd)
+	var rec recType
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		var list []recType
+		db.Query(&rec, "")
+		for db.Next() {
+			list = append(list, rec)
+		}
+	}
+	b.StopTimer()
+	if !db.OK() {
+		b.Fatal(db.Err())
+	}
+	hnd.Close()
+}
+
+// BenchmarkQueryAll measures the retrieval of records into a new slice with
+// QueryAll(), for comparison with BenchmarkQueryReuse.
+func BenchmarkQueryAll(b *testing.B) {
+	hnd := benchOpen(b, 1000)
+	db := glRecDsc.Wrap(hnd)
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		var list []recType
+		db.QueryAll(&list, "")
+	}
+	b.StopTimer()
+	if !db.OK() {
+		b.Fatal(db.Err())
+	}
+	hnd.Close()
+}
+
+// BenchmarkDescribe measures the generation of a descriptor.
+func BenchmarkDescribe(b *testing.B) {
+	for j := 0; j < b.N; j++ {
+		_, err := dbmap.Describe(recType{})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDescribeCached measures the retrieval of a cached descriptor.
+func BenchmarkDescribeCached(b *testing.B) {
+	for j := 0; j < b.N; j++ {
+		_, err := dbmap.DescribeCached(recType{})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkUpdateAll measures the update of 10,000 records in a transaction
+// with UpdateAll().
+func BenchmarkUpdateAll(b *testing.B) {
+	hnd := benchOpen(b, 10000)
+	db := glRecDsc.Wrap(hnd)
+	var list []recType
+	db.QueryAll(&list, "")
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		for k := range list {
+			list[k].Num++
+		}
+		db.TransactionBegin()
+		db.UpdateAll(list, "num")
+		db.TransactionEnd()
+	}
+	b.StopTimer()
+	if !db.OK() {
+		b.Fatal(db.Err())
+	}
+	hnd.Close()
+}
+
+// BenchmarkUpdateLoop measures the update of 10,000 records in a transaction
+// with a command that is prepared for each record.
+func BenchmarkUpdateLoop(b *testing.B) {
+	hnd := benchOpen(b, 10000)
+	db := glRecDsc.Wrap(hnd)
+	var list []recType
+	db.QueryAll(&list, "")
+	cmdStr := glRecDsc.UpdateStr("num")
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		tx, err := hnd.Begin()
+		for k := 0; k < len(list) && err == nil; k++ {
+			list[k].Num++
+			var args []interface{}
+			args, err = glRecDsc.UpdateArg(list[k], "num")
+			if err == nil {
+				_, err = tx.Exec(cmdStr, args...)
+			}
+		}
+		if err == nil {
+			err = tx.Commit()
+		} else if tx != nil {
+			tx.Rollback()
+		}
+		db.SetError(err)
+	}
+	b.StopTimer()
+	if !db.OK() {
+		b.Fatal(db.Err())
+	}
+	hnd.Close()
+}
+
+// This example demonstrates the use of a table whose name is an SQL reserved
+// word. Such names are quoted automatically in the generated commands.
+func ExampleDscType_08() {
+	type orderType struct {
+		ID   int64  `db_primary:"*" db_table:"order"`
+		Item string `db:"item" db_index:"item1"`
+		Qty  int64  `db:"qty"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(orderType{})
+		fmt.Println(dsc.SelectStr("ORDER BY item"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		var rec orderType
+		for _, rec = range []orderType{{0, "pencil", 12}, {0, "eraser", 3}} {
+			db.Insert(&rec)
+		}
+		rec.Qty = 4
+		db.Update(&rec, "qty")
+		db.Query(&rec, "ORDER BY item")
+		for db.Next() {
+			fmt.Printf("%d %s %d\n", rec.ID, rec.Item, rec.Qty)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// SELECT rowid, item, qty FROM "order" ORDER BY item;
+	// 2 eraser 4
+	// 1 pencil 12
+}
+
+// This example demonstrates the insertion of a batch of records. When one of
+// the records cannot be inserted, the retained error identifies its position
+// within the batch.
+func ExampleDscType_09() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		_, err = hnd.Exec("CREATE UNIQUE INDEX rec_unique ON rec (num)")
+		db.SetError(err)
+		list := []recType{{0, "one", 1}, {0, "two", 2}, {0, "three", 3}}
+		db.InsertBatch(list)
+		for _, rec := range list {
+			fmt.Printf("Inserted [%d][%s]\n", rec.ID, rec.Str)
+		}
+		db.InsertBatch([]*recType{{0, "four", 4}, {0, "five", 5}, {0, "uno", 1}, {0, "six", 6}})
+		var batchErr *dbmap.BatchError
+		if errors.As(db.Err(), &batchErr) {
+			fmt.Printf("Failed at index %d\n", batchErr.Index)
+			db.ClearError()
+		}
+		var rec recType
+		db.Query(&rec, "ORDER BY num")
+		for db.Next() {
+			fmt.Printf("Stored [%d][%s]\n", rec.ID, rec.Str)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// Inserted [1][one]
+	// Inserted [2][two]
+	// Inserted [3][three]
+	// Failed at index 2
+	// Stored [1][one]
+	// Stored [2][two]
+	// Stored [3][three]
+	// Stored [4][four]
+	// Stored [5][five]
+}
+
+// This example demonstrates an enumerated field. A field of a named integer
+// type is stored as an integer column, and registering its valid values with
+// WithEnum() causes writes of any other value to be rejected before they reach
+// the database.
+func ExampleDscType_10() {
+	type statusType int
+	const (
+		statusOpen statusType = iota + 1
+		statusClosed
+	)
+	type ticketType struct {
+		ID     int64      `db_primary:"*" db_table:"ticket"`
+		Status statusType `db:"status"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var dsc dbmap.DscType
+		dsc, err = dbmap.MustDescribe(ticketType{}).WithEnum("status", statusOpen, statusClosed)
+		if err == nil {
+			db := dsc.Wrap(hnd)
+			db.Create()
+			rec := ticketType{Status: statusOpen}
+			db.Insert(&rec)
+			rec.Status = 7
+			db.Update(&rec)
+			fmt.Println(db.Err())
+			db.ClearError()
+			rec.Status = statusClosed
+			db.Update(&rec)
+			db.QueryRow(&rec, "WHERE rowid = ?", rec.ID)
+			fmt.Println(rec.ID, rec.Status == statusClosed)
+			err = db.Err()
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// dbmap update ticket: value 7 is not a registered enumeration value of field "status"
+	// 1 true
+}
+
+// This example demonstrates timestamp fields. A field of type *time.Time
+// holds a nullable timestamp.
+func ExampleDscType_11() {
+	type eventType struct {
+		ID      int64      `db_primary:"*" db_table:"event"`
+		Created time.Time  `db:"created"`
+		Closed  *time.Time `db:"closed"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(eventType{}).Wrap(hnd)
+		db.Create()
+		tm := time.Date(2015, time.March, 12, 8, 30, 15, 0, time.UTC)
+		db.Insert(&eventType{Created: tm})
+		db.Insert(&eventType{Created: tm.Add(time.Hour), Closed: &tm})
+		db.Insert(&eventType{})
+		var rec eventType
+		db.Query(&rec, "ORDER BY rowid")
+		for db.Next() {
+			closedStr := "open"
+			if rec.Closed != nil {
+				closedStr = rec.Closed.Format(time.RFC3339)
+			}
+			fmt.Printf("%d %s %v %s\n", rec.ID, rec.Created.UTC().Format(time.RFC3339),
+				rec.Created.IsZero(), closedStr)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 1 2015-03-12T08:30:15Z false open
+	// 2 2015-03-12T09:30:15Z false 2015-03-12T08:30:15Z
+	// 3 0001-01-01T00:00:00Z true open
+}
+
+// This example demonstrates the preview of commands. Each Build method returns
+// the command string and arguments that the corresponding WrapType method
+// would execute, without accessing the database.
+func ExampleDscType_12() {
+	rec := recType{ID: 42, Str: "abc", Num: 7}
+	show := func(cmdStr string, args []interface{}, err error) {
+		if err == nil {
+			fmt.Println(cmdStr, args)
+		} else {
+			fmt.Println(err)
+		}
+	}
+	show(glRecDsc.BuildInsert(&rec))
+	show(glRecDsc.BuildUpdate(rec, "num"))
+	show(glRecDsc.BuildUpdate(rec))
+	show(glRecDsc.BuildDelete("WHERE num > ?", 5))
+	show(glRecDsc.BuildSelect(&rec, "WHERE str = ? AND num < ?", "abc", 10))
+	show(glRecDsc.BuildSelect(rec, ""))
+	// Output:
+	// INSERT INTO rec (str, num) VALUES (?, ?); [abc 7]
+	// UPDATE rec SET num = ? WHERE rowid = ?; [7 42]
+	// UPDATE rec SET str = ?, num = ? WHERE rowid = ?; [abc 7 42]
+	// DELETE FROM rec WHERE num > ?; [5]
+	// SELECT rowid, str, num FROM rec WHERE str = ? AND num < ?; [abc 10]
+	// passed-in value must be a structure pointer
+}
+
+// This example demonstrates fields of the database/sql Null types, which can
+// hold NULL values. Reading a NULL value into a field that cannot hold it
+// results in an error that identifies the column.
+func ExampleDscType_13() {
+	type nullType struct {
+		ID    int64           `db_primary:"*" db_table:"person"`
+		Name  sql.NullString  `db:"name"`
+		Age   sql.NullInt64   `db:"age"`
+		Score sql.NullFloat64 `db:"score"`
+		Admin sql.NullBool    `db:"admin"`
+	}
+	type plainType struct {
+		ID   int64  `db_primary:"*" db_table:"person"`
+		Name string `db:"name"`
+		Age  int64  `db:"age"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(nullType{}).Wrap(hnd)
+		db.Create()
+		db.Insert(&nullType{Name: sql.NullString{String: "Athos", Valid: true},
+			Age: sql.NullInt64{Int64: 42, Valid: true}, Admin: sql.NullBool{Bool: true, Valid: true}})
+		db.Insert(&nullType{Name: sql.NullString{String: "Porthos", Valid: true}})
+		var rec nullType
+		db.Query(&rec, "ORDER BY rowid")
+		for db.Next() {
+			fmt.Printf("%s %v %v %v %v\n", rec.Name.String, rec.Age.Valid, rec.Age.Int64,
+				rec.Score.Valid, rec.Admin.Bool)
+		}
+		plainDb := dbmap.MustDescribe(plainType{}).WrapJoin(db)
+		var plain plainType
+		plainDb.QueryRow(&plain, "WHERE name = ?", "Athos")
+		fmt.Println(plain.Name, plain.Age)
+		plainDb.QueryRow(&plain, "WHERE name = ?", "Porthos")
+		fmt.Println(plainDb.Err())
+		plainDb.ClearError()
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// Athos true 42 false true
+	// Porthos false 0 false false
+	// Athos 42
+	// dbmap query person: column "age" is NULL but its field of type int64 cannot hold NULL; use a pointer or sql.Null type instead
+}
+
+// This example demonstrates the removal of a table and its indexes. With the
+// IF EXISTS variant, dropping a table that does not exist is not an error.
+func ExampleDscType_14() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dropStr, idxList := glRecDsc.DropStr(true)
+		fmt.Println(dropStr, len(idxList))
+		db := glRecDsc.Wrap(hnd)
+		db.Drop(true)
+		db.Create()
+		db.Insert(&recType{Str: "abc", Num: 1})
+		db.Drop(false)
+		db.Create()
+		fmt.Println(db.OK())
+		db.Drop(true)
+		db.Drop(false)
+		fmt.Println(db.OK())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// DROP TABLE IF EXISTS rec; 2
+	// true
+	// false
+}
+
+// This example demonstrates that a failed scan within a Query() and Next()
+// loop closes the result set, so the connection it held is released.
+func ExampleDscType_15() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		hnd.SetMaxOpenConns(1)
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&recType{Str: "abc", Num: 1})
+		_, err = hnd.Exec("INSERT INTO rec (str, num) VALUES ('def', 'not a number')")
+		db.SetError(err)
+		var rec recType
+		db.Query(&rec, "ORDER BY rowid")
+		for db.Next() {
+			fmt.Println(rec.Str)
+		}
+		fmt.Println(db.OK(), hnd.Stats().InUse)
+		db.ClearError()
+		db.QueryRow(&rec, "WHERE num = ?", 1)
+		fmt.Println(rec.Str)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// abc
+	// false 0
+	// abc
+}
+
+// This example demonstrates counting records without retrieving them.
+func ExampleDscType_16() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var j int64
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.TransactionBegin()
+		for j = 1; j <= 10; j++ {
+			db.Insert(&recType{Str: hashStr(j), Num: j})
+		}
+		fmt.Println(db.Count(""))
+		db.TransactionEnd()
+		fmt.Println(glRecDsc.CountStr("WHERE num > ?"))
+		fmt.Println(db.Count("WHERE num > ?", 7))
+		fmt.Println(db.Count("WHERE bogus > ?", 7), db.OK())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 10
+	// SELECT COUNT(*) FROM rec WHERE num > ?;
+	// 3
+	// 0 false
+}
+
+// This example demonstrates the assembly of a WHERE clause from optional
+// conditions. Inactive conditions are omitted and the WHERE keyword is
+// included only if at least one condition is active.
+func ExampleDscType_17() {
+	filter := func(minNum int64, strPrefix string) {
+		tailStr, args, err := glRecDsc.WhereAll(
+			dbmap.CondIf(minNum > 0, "num >= ?", minNum),
+			dbmap.CondIf(len(strPrefix) > 0, "str LIKE ?", strPrefix+"%"))
+		if err == nil {
+			fmt.Printf("[%s] %v\n", tailStr, args)
+		} else {
+			fmt.Println(err)
+		}
+	}
+	filter(0, "")
+	filter(5, "")
+	filter(5, "ab")
+	_, _, err := glRecDsc.WhereAll(dbmap.Cond{ExprStr: "num = ? OR num = ?", Args: []interface{}{1}})
+	fmt.Println(err)
+	// Output:
+	// [] []
+	// [WHERE num >= ?] [5]
+	// [WHERE (num >= ?) AND (str LIKE ?)] [5 ab%]
+	// condition "num = ? OR num = ?" has 2 placeholders but 1 parameters
+}
+
+// This example demonstrates a strict table, in which SQLite rejects values
+// that do not match the declared column type.
+func ExampleDscType_18() {
+	type strictType struct {
+		ID      int64     `db_primary:"*" db_table:"strict_rec"`
+		Num     int64     `db:"num"`
+		Created time.Time `db:"created"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(strictType{}).WithStrict(true)
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&strictType{Num: 12, Created: time.Date(2015, 3, 12, 0, 0, 0, 0, time.UTC)})
+		fmt.Println(db.OK())
+		_, err = hnd.Exec("INSERT INTO strict_rec (num, created) VALUES ('twelve', '')")
+		fmt.Println(err != nil)
+		fmt.Println(db.Count(""))
+		var rec strictType
+		db.QueryRow(&rec, "")
+		fmt.Println(rec.Num, rec.Created.Format("2006-01-02"))
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE strict_rec (num integer, created text) STRICT;
+	// true
+	// true
+	// 1
+	// 12 2015-03-12
+}
+
+// This example demonstrates the total number of rows affected by the write
+// operations within a transaction.
+func ExampleDscType_19() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var j int64
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.TransactionBegin()
+		for j = 1; j <= 5; j++ {
+			db.Insert(&recType{Str: hashStr(j), Num: j})
+		}
+		fmt.Println(db.TransactionRowsAffected())
+		db.Delete("WHERE num > ?", 2)
+		fmt.Println(db.TransactionRowsAffected())
+		db.TransactionEnd()
+		fmt.Println(db.TransactionRowsAffected(), db.Count(""))
+		db.TransactionBegin()
+		db.Delete("")
+		fmt.Println(db.TransactionRowsAffected())
+		db.TransactionRollback()
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 5
+	// 8
+	// 0 2
+	// 2
+}
+
+// This example demonstrates a table with a composite key. Fields tagged with
+// both "db" and "db_primary" together identify a record when it is updated.
+func ExampleDscType_20() {
+	type itemType struct {
+		TenantID int64  `db:"tenant" db_primary:"*" db_table:"item"`
+		Code     string `db:"code" db_primary:"*"`
+		Label    string `db:"label"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(itemType{})
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		fmt.Println(dsc.UpdateStr("label"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		for _, rec := range []itemType{{1, "A", "one-a"}, {1, "B", "one-b"}, {2, "A", "two-a"}} {
+			db.Insert(rec)
+		}
+		db.Update(itemType{2, "A", "changed"}, "label")
+		var rec itemType
+		db.Query(&rec, "ORDER BY tenant, code")
+		for db.Next() {
+			fmt.Println(rec.TenantID, rec.Code, rec.Label)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE item (tenant integer, code text, label text, PRIMARY KEY (tenant, code));
+	// UPDATE item SET label = ? WHERE tenant = ? AND code = ?;
+	// 1 A one-a
+	// 1 B one-b
+	// 2 A changed
+}
+
+// This example demonstrates a query that is abandoned by canceling its
+// context. Once the context is canceled, Next() returns false and the
+// cancellation is retained as the wrapper's error.
+func ExampleDscType_21() {
+	type recType struct {
+		ID  int64 `db_primary:"*" db_table:"rec"`
+		Num int64 `db:"num"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(recType{}).Wrap(hnd)
+		db.Create()
+		db.TransactionBegin()
+		for j := int64(0); j < 100; j++ {
+			db.Insert(&recType{Num: j})
+		}
+		db.TransactionCommit()
+		ctx, cancel := context.WithCancel(context.Background())
+		var rec recType
+		db.QueryContext(ctx, &rec, "ORDER BY num")
+		if db.Next() {
+			fmt.Println(rec.Num)
+		}
+		cancel()
+		for db.Next() {
+		}
+		fmt.Println(errors.Is(db.Err(), context.Canceled))
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 0
+	// true
+}
+
+// This example demonstrates a descriptor built at runtime from a column
+// specification rather than from a tagged structure. Rows are retrieved into
+// a map keyed by column name or into a slice with one value per column.
+func ExampleDescribeColumns() {
+	type cityType struct {
+		ID   int64  `db_primary:"*" db_table:"city"`
+		Name string `db:"name"`
+		Pop  int64  `db:"pop"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(cityType{}).Wrap(hnd)
+		db.Create()
+		db.Insert(&cityType{Name: "Ames", Pop: 66000})
+		db.Insert(&cityType{Name: "Boise", Pop: 237000})
+		dsc := dbmap.DescribeColumns("city", []dbmap.ColumnSpec{
+			{Name: "name", Kind: reflect.String},
+			{Name: "pop", Kind: reflect.Int32},
+		})
+		fmt.Println(dsc.SelectStr("ORDER BY name"))
+		dyn := dsc.Wrap(hnd)
+		var rec map[string]interface{}
+		dyn.Query(&rec, "ORDER BY name")
+		for dyn.Next() {
+			fmt.Printf("%v %v (%T)\n", rec["name"], rec["pop"], rec["pop"])
+		}
+		var row []interface{}
+		dyn.QueryRow(&row, "WHERE pop > ?", 100000)
+		fmt.Println(row...)
+		err = dyn.Err()
+		if err == nil {
+			dyn.Insert(&row)
+			fmt.Println(dyn.Err())
+			dyn.ClearError()
+			dyn.QueryCols(&row, []string{"name"}, "")
+			fmt.Println(dyn.Err())
+			dyn.ClearError()
+		}
+		hnd.Close()
+		if err == nil {
+			err = db.Err()
+		}
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// SELECT name, pop FROM city ORDER BY name;
+	// Ames 66000 (int32)
+	// Boise 237000 (int32)
+	// Boise 237000
+	// dbmap insert into city: descriptor of table city is read-only
+	// dbmap query city: descriptor of table city does not support retrieval of selected columns
+}
+
+// This example demonstrates a unique index that ignores case. A field tagged
+// with "db_unique" is a member of an index that rejects duplicate keys, and a
+// collation following a key segment applies to that segment.
+func ExampleDscType_22() {
+	type memberType struct {
+		ID    int64  `db_primary:"*" db_table:"member"`
+		Email string `db:"email" db_unique:"email1 NOCASE"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(memberType{})
+		_, idxList := dsc.CreateStr()
+		fmt.Println(strings.Join(idxList, "\n"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&memberType{Email: "A@B.com"})
+		db.Insert(&memberType{Email: "a@b.com"})
+		fmt.Println(db.Err())
+		db.ClearError()
+		fmt.Println(db.Count(""))
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE UNIQUE INDEX member_email ON member (email COLLATE NOCASE)
+	// dbmap insert into member: UNIQUE constraint failed: member.email
+	// 1
+}
+
+// This example demonstrates the retrieval of a subset of columns. Fields that
+// are not named keep their zero value.
+func ExampleDscType_23() {
+	type wideType struct {
+		ID    int64  `db_primary:"*" db_table:"wide"`
+		Name  string `db:"name"`
+		Notes string `db:"notes"`
+		Num   int64  `db:"num"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(wideType{})
+		cols := []string{"name", "num"}
+		fmt.Println(dsc.SelectColsStr(cols, "ORDER BY num"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&wideType{Name: "alpha", Notes: "long text", Num: 2})
+		db.Insert(&wideType{Name: "beta", Notes: "more text", Num: 1})
+		var rec wideType
+		db.QueryCols(&rec, cols, "ORDER BY num")
+		for db.Next() {
+			fmt.Printf("%d [%s] [%s] %d\n", rec.ID, rec.Name, rec.Notes, rec.Num)
+		}
+		db.QueryCols(&rec, []string{"name", "size"}, "")
+		fmt.Println(db.Err())
+		db.ClearError()
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// SELECT name, num FROM wide ORDER BY num;
+	// 0 [beta] [] 1
+	// 0 [alpha] [] 2
+	// dbmap query wide: column "size" is not a "db" tag of dbmap_test.wideType
+}
+
+// This example demonstrates an upsert. When the insertion conflicts with an
+// existing record, only the named columns of that record are updated; its
+// identifier and its other columns are preserved.
+func ExampleDscType_24() {
+	type userType struct {
+		ID     int64  `db_primary:"*" db_table:"user"`
+		Email  string `db:"email" db_unique:"email1"`
+		Name   string `db:"name"`
+		Visits int64  `db:"visits"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(userType{})
+		conflictCols := []string{"email"}
+		updateCols := []string{"name"}
+		fmt.Println(dsc.UpsertStr(conflictCols, updateCols))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&userType{Email: "a@b.com", Name: "Al", Visits: 7})
+		db.Insert(&userType{Email: "c@d.com", Name: "Cy", Visits: 3})
+		rec := userType{Email: "a@b.com", Name: "Alan"}
+		db.Upsert(&rec, conflictCols, updateCols)
+		fmt.Println(rec.ID)
+		db.QueryRow(&rec, "WHERE email = ?", "a@b.com")
+		fmt.Println(rec.ID, rec.Email, rec.Name, rec.Visits)
+		fmt.Println(db.Count(""))
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// INSERT INTO user (email, name, visits) VALUES (?, ?, ?) ON CONFLICT (email) DO UPDATE SET name = excluded.name RETURNING rowid;
+	// 1
+	// 1 a@b.com Alan 7
+	// 2
+}
+
+// This example demonstrates database maintenance. These commands cannot be
+// run while a transaction is active.
+func ExampleWrapType_Vacuum() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(recType{}).Wrap(hnd)
+		db.Create()
+		db.TransactionBegin()
+		db.Vacuum()
+		fmt.Println(db.Err())
+		db.ClearError()
+		db.Optimize()
+		fmt.Println(db.Err())
+		db.ClearError()
+		db.TransactionRollback()
+		db.Vacuum()
+		db.Analyze()
+		db.Optimize()
+		fmt.Println(db.OK())
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// VACUUM cannot be run within a transaction
+	// PRAGMA optimize cannot be run within a transaction
+	// true
+}
+
+// This example demonstrates positional binding of a hand-written query. The
+// query selects the identifier and name columns in the order in which the
+// descriptor selects them.
+func ExampleDscType_BindPositional() {
+	type itemType struct {
+		ID   int64  `db_primary:"*" db_table:"item"`
+		Name string `db:"name"`
+		Num  int64  `db:"num"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(itemType{})
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&itemType{Name: "bolt", Num: 12})
+		db.Insert(&itemType{Name: "nut", Num: 30})
+		err = db.Err()
+		if err == nil {
+			var rec itemType
+			var args []interface{}
+			args, err = dsc.BindPositional(&rec, 2)
+			if err == nil {
+				err = hnd.QueryRow("SELECT rowid, upper(name) FROM item WHERE num > 20").Scan(args...)
+				fmt.Println(rec.ID, rec.Name, rec.Num)
+			}
+			if err == nil {
+				_, err = dsc.BindPositional(&rec, 4)
+				fmt.Println(err)
+				err = nil
+			}
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 2 NUT 0
+	// column count 4 is outside the range 1 to 3 of selected fields
+}
+
+// This example demonstrates the use of the table and column names of a
+// descriptor to build a custom command.
+func ExampleDscType_TableName() {
+	type saleType struct {
+		ID     int64  `db_primary:"*" db_table:"sale"`
+		Region string `db:"region"`
+		Amount int64  `db:"amount"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(saleType{})
+		keyStr, ok := dsc.PrimaryKey()
+		fmt.Println(dsc.TableName(), dsc.Columns(), keyStr, ok)
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&saleType{Region: "east", Amount: 5})
+		db.Insert(&saleType{Region: "west", Amount: 7})
+		db.Insert(&saleType{Region: "east", Amount: 4})
+		err = db.Err()
+		if err == nil {
+			cols := dsc.Columns()
+			cmdStr := fmt.Sprintf("SELECT %s, SUM(%s) FROM %s GROUP BY %s ORDER BY %s",
+				cols[0], cols[1], dsc.TableName(), cols[0], cols[0])
+			var rows *sql.Rows
+			rows, err = hnd.Query(cmdStr)
+			if err == nil {
+				var regionStr string
+				var sum int64
+				for err == nil && rows.Next() {
+					err = rows.Scan(&regionStr, &sum)
+					fmt.Println(regionStr, sum)
+				}
+				rows.Close()
+			}
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// sale [region amount] rowid true
+	// east 9
+	// west 7
+}
+
+// This example demonstrates NOT NULL column constraints. A field that can
+// hold NULL, such as a pointer, cannot be tagged "db_notnull".
+func ExampleDscType_25() {
+	type partType struct {
+		ID    int64          `db_primary:"*" db_table:"part"`
+		Name  string         `db:"name" db_notnull:"*" db_collate:"NOCASE"`
+		Notes sql.NullString `db:"notes"`
+	}
+	type badType struct {
+		ID   int64      `db_primary:"*" db_table:"bad"`
+		When *time.Time `db:"when" db_notnull:"*"`
+	}
+	createStr, _ := dbmap.MustDescribe(partType{}).CreateStr()
+	fmt.Println(createStr)
+	_, err := dbmap.Describe(badType{})
+	fmt.Println(err)
+	// Output:
+	// CREATE TABLE part (name text NOT NULL COLLATE NOCASE, notes text);
+	// field When of type *time.Time can hold NULL and cannot be tagged "db_notnull"
+}
+
+// This example demonstrates the transactional outbox pattern. The event
+// inserted into the outbox table is committed or rolled back together with the
+// business record written in the same transaction.
+func ExampleWrapType_InsertOutbox() {
+	type orderType struct {
+		ID   int64  `db_primary:"*" db_table:"orders"`
+		Item string `db:"item"`
+	}
+	type eventType struct {
+		ID      int64  `db_primary:"*" db_table:"outbox"`
+		Kind    string `db:"kind"`
+		Payload string `db:"payload"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		outboxDsc := dbmap.MustDescribe(eventType{})
+		db := dbmap.MustDescribe(orderType{}).Wrap(hnd)
+		outbox := outboxDsc.WrapJoin(db)
+		db.Create()
+		outbox.Create()
+		db.InsertOutbox(outboxDsc, &eventType{Kind: "none"})
+		fmt.Println(db.Err())
+		db.ClearError()
+		for _, commit := range []bool{true, false} {
+			db.TransactionBegin()
+			db.InsertClear()
+			rec := orderType{Item: "lamp"}
+			db.Insert(&rec)
+			db.InsertOutbox(outboxDsc, &eventType{Kind: "created", Payload: rec.Item})
+			if commit {
+				db.TransactionCommit()
+			} else {
+				db.TransactionRollback()
+			}
+			fmt.Println(commit, db.Count(""), outbox.Count(""))
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// outbox insertion requires an active transaction
+	// true 1 1
+	// false 1 1
+}
+
+// This example demonstrates a ranked search. A record in which a column
+// begins with the search term ranks above one in which the term appears later.
+// The scoring expression can be replaced, here with one that favors shorter
+// names.
+func ExampleWrapType_Search() {
+	type fruitType struct {
+		ID   int64  `db_primary:"*" db_table:"fruit"`
+		Name string `db:"name"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(fruitType{}).Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"pineapple", "grape", "apple_pie", "apple"} {
+			db.Insert(&fruitType{Name: str})
+		}
+		var rec fruitType
+		show := func(termStr string, opt dbmap.SearchOpt) {
+			var list []string
+			db.Search(&rec, []string{"name"}, termStr, opt)
+			for db.Next() {
+				list = append(list, rec.Name)
+			}
+			fmt.Println(list)
+		}
+		show("apple", dbmap.SearchOpt{Rank: true})
+		show("e_p", dbmap.SearchOpt{})
+		show("apple", dbmap.SearchOpt{Rank: true,
+			Score: func(colStr, termStr string, bind func(interface{}) string) string {
+				return fmt.Sprintf("-length(%s)", colStr)
+			}})
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// [apple_pie apple pineapple]
+	// [apple_pie]
+	// [apple pineapple apple_pie]
+}
+
+// This example demonstrates uniqueness constraints. An asterisk declares a
+// unique index on a single column, and a segment list declares one on several
+// columns.
+func ExampleDscType_26() {
+	type acctType struct {
+		ID     int64  `db_primary:"*" db_table:"acct"`
+		Email  string `db:"email" db_unique:"*"`
+		Region string `db:"region" db_unique:"handle1"`
+		Handle string `db:"handle" db_unique:"handle2"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(acctType{})
+		_, idxList := dsc.CreateStr()
+		sort.Strings(idxList)
+		fmt.Println(strings.Join(idxList, "\n"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&acctType{Email: "a@b.com", Region: "east", Handle: "al"})
+		db.Insert(&acctType{Email: "c@d.com", Region: "west", Handle: "al"})
+		fmt.Println(db.Err())
+		db.Insert(&acctType{Email: "a@b.com", Region: "north", Handle: "cy"})
+		fmt.Println(db.Err())
+		db.ClearError()
+		db.Insert(&acctType{Email: "e@f.com", Region: "east", Handle: "al"})
+		fmt.Println(db.Err())
+		db.ClearError()
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE UNIQUE INDEX acct_email ON acct (email)
+	// CREATE UNIQUE INDEX acct_handle ON acct (region, handle)
+	// <nil>
+	// dbmap insert into acct: UNIQUE constraint failed: acct.email
+	// dbmap insert into acct: UNIQUE constraint failed: acct.region, acct.handle
+}
+
+// This example demonstrates default column values. String values are quoted,
+// numbers are used as they are, and a leading equal sign marks an SQL
+// expression. The defaults apply to rows inserted without the columns.
+func ExampleDscType_27() {
+	type taskType struct {
+		ID       int64  `db_primary:"*" db_table:"task"`
+		Title    string `db:"title"`
+		Status   string `db:"status" db_default:"it's new"`
+		Priority int64  `db:"priority" db_default:"3"`
+		Created  string `db:"created" db_default:"=CURRENT_DATE"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(taskType{})
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		db := dsc.Wrap(hnd)
+		db.Create()
+		err = db.Err()
+		if err == nil {
+			_, err = hnd.Exec("INSERT INTO task (title) VALUES (?)", "dishes")
+		}
+		if err == nil {
+			var rec taskType
+			db.QueryRow(&rec, "WHERE title = ?", "dishes")
+			fmt.Println(rec.Title, rec.Status, rec.Priority,
+				rec.Created == time.Now().UTC().Format("2006-01-02"))
+			err = db.Err()
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE task (title text, status text DEFAULT 'it''s new', priority integer DEFAULT 3, created text DEFAULT CURRENT_DATE);
+	// dishes it's new 3 true
+}
+
+// This example demonstrates the trimming of string fields on write. The value
+// "lower" also converts the value to lower case. The record itself is not
+// modified.
+func ExampleDscType_28() {
+	type contactType struct {
+		ID    int64  `db_primary:"*" db_table:"contact"`
+		Name  string `db:"name" db_trim:"*"`
+		Email string `db:"email" db_trim:"lower"`
+		Note  string `db:"note"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(contactType{}).Wrap(hnd)
+		db.Create()
+		rec := contactType{Name: " Ann Lee  ", Email: " Ann@Example.COM\n", Note: " as is "}
+		db.Insert(&rec)
+		fmt.Printf("[%s]\n", rec.Name)
+		var get contactType
+		db.QueryRow(&get, "WHERE rowid = ?", rec.ID)
+		fmt.Printf("[%s] [%s] [%s]\n", get.Name, get.Email, get.Note)
+		get.Name = "Ann  "
+		get.Note = " still as is "
+		db.Update(get, "note")
+		db.QueryRow(&get, "WHERE rowid = ?", rec.ID)
+		fmt.Printf("[%s] [%s] [%s]\n", get.Name, get.Email, get.Note)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// [ Ann Lee  ]
+	// [Ann Lee] [ann@example.com] [ as is ]
+	// [Ann Lee] [ann@example.com] [ still as is ]
+}
+
+// This example demonstrates the schema version that applications can use to
+// keep track of migrations. With SQLite, the version is kept in the database
+// file, so it persists after the database is closed and reopened.
+func ExampleWrapType_SchemaVersion() {
+	var hnd *sql.DB
+	var err error
+	var v int
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		v, err = db.SchemaVersion()
+		fmt.Println(v, err)
+		db.SetSchemaVersion(7)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err == nil {
+		hnd, err = sql.Open("sqlite3", dbFileStr)
+		if err == nil {
+			db := glRecDsc.Wrap(hnd)
+			v, err = db.SchemaVersion()
+			fmt.Println(v, err)
+			hnd.Close()
+		}
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 0 <nil>
+	// 7 <nil>
+}
+
+// This example demonstrates timestamps that are maintained automatically. A
+// field tagged "db_autocreate" is set when the record is inserted, and one
+// tagged "db_autoupdate" is set when it is inserted or updated, even if it is
+// not named in a partial update. The clock is replaced here to make the
+// times predictable.
+func ExampleDscType_29() {
+	type docType struct {
+		ID      int64     `db_primary:"*" db_table:"doc"`
+		Title   string    `db:"title"`
+		Created time.Time `db:"created" db_autocreate:"*"`
+		Updated time.Time `db:"updated" db_autoupdate:"*"`
+	}
+	tm := time.Date(2020, 5, 1, 9, 0, 0, 0, time.UTC)
+	dbmap.NowFunc = func() time.Time { return tm }
+	defer func() { dbmap.NowFunc = time.Now }()
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(docType{})
+		fmt.Println(dsc.UpdateStr("title"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		rec := docType{Title: "draft"}
+		db.Insert(&rec)
+		fmt.Println(rec.Created.Format(time.Kitchen), rec.Updated.Format(time.Kitchen))
+		tm = tm.Add(90 * time.Minute)
+		rec.Title = "final"
+		db.Update(&rec, "title")
+		var get docType
+		db.QueryRow(&get, "WHERE rowid = ?", rec.ID)
+		fmt.Println(get.Title, get.Created.Format(time.Kitchen), get.Updated.Format(time.Kitchen))
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// UPDATE doc SET title = ?, updated = ? WHERE rowid = ?;
+	// 9:00AM 9:00AM
+	// final 9:00AM 10:30AM
+}
+
+// This example demonstrates soft deletion. Deleting a record whose structure
+// has a field tagged "db_softdelete" marks it as deleted rather than removing
+// it. Such records are excluded from queries unless QueryWithDeleted() is
+// used, and DeleteHard() removes them.
+func ExampleWrapType_QueryWithDeleted() {
+	type noteType struct {
+		ID      int64     `db_primary:"*" db_table:"note"`
+		Text    string    `db:"text"`
+		Deleted time.Time `db:"deleted" db_softdelete:"*"`
+	}
+	tm := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	dbmap.NowFunc = func() time.Time { return tm }
+	defer func() { dbmap.NowFunc = time.Now }()
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(noteType{})
+		fmt.Println(dsc.SelectStr("ORDER BY rowid"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"keep", "drop", "also keep"} {
+			db.Insert(&noteType{Text: str})
+		}
+		db.Delete("WHERE text = ?", "drop")
+		var rec noteType
+		show := func() {
+			for db.Next() {
+				fmt.Println(rec.ID, rec.Text, rec.Deleted.IsZero())
+			}
+		}
+		db.Query(&rec, "ORDER BY rowid")
+		show()
+		fmt.Println(db.Count(""))
+		db.QueryWithDeleted(&rec, "ORDER BY rowid")
+		show()
+		db.QueryRow(&rec, "WHERE text = ?", "drop")
+		fmt.Println(db.Err())
+		db.ClearError()
+		db.DeleteHard("WHERE text = ?", "drop")
+		db.QueryWithDeleted(&rec, "WHERE rowid = 2")
+		show()
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// SELECT rowid, text, deleted FROM (SELECT rowid, * FROM note WHERE deleted IS NULL) AS note ORDER BY rowid;
+	// 1 keep true
+	// 3 also keep true
+	// 2
+	// 1 keep true
+	// 2 drop false
+	// 3 also keep true
+	// dbmap query note: sql: no rows in result set
+}
+
+// This example demonstrates the detection of differences between the indexes
+// that a descriptor declares and those of the table in the database.
+func ExampleWrapType_IndexDrift() {
+	type bookType struct {
+		ID     int64  `db_primary:"*" db_table:"book"`
+		Title  string `db:"title" db_index:"title1"`
+		Author string `db:"author" db_index:"author1"`
+		ISBN   string `db:"isbn" db_unique:"*"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(bookType{}).Wrap(hnd)
+		db.Create()
+		fmt.Println(db.IndexDrift())
+		_, err = hnd.Exec("DROP INDEX book_author")
+		if err == nil {
+			_, err = hnd.Exec("CREATE INDEX book_extra ON book (author, title)")
+		}
+		if err == nil {
+			fmt.Println(db.IndexDrift())
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// [] [] <nil>
+	// [book_author] [book_extra] <nil>
+}
+
+// This example demonstrates a migration plan for a table that was created
+// before a column and an index were added to its record structure.
+func ExampleWrapType_MigrationPlan() {
+	type bookType struct {
+		ID     int64  `db_primary:"*" db_table:"book"`
+		Title  string `db:"title" db_index:"title1"`
+		Author string `db:"author" db_index:"author1"`
+		Year   int    `db:"year" db_default:"0"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		_, err = hnd.Exec("CREATE TABLE book (title text, author text, note text)")
+		if err == nil {
+			_, err = hnd.Exec("CREATE INDEX book_title ON book (title)")
+		}
+		if err == nil {
+			db := dbmap.MustDescribe(bookType{}).Wrap(hnd)
+			fmt.Println(db.ColumnDrift())
+			var cmdList []string
+			cmdList, err = db.MigrationPlan()
+			for _, cmdStr := range cmdList {
+				fmt.Println(cmdStr)
+			}
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// [year] [note] <nil>
+	// ALTER TABLE book ADD COLUMN year integer DEFAULT 0;
+	// CREATE INDEX book_author ON book (author)
+}
+
+// This example demonstrates the construction of the tail of a SELECT command
+// with TailType. The result can be passed to Query() in place of a tail string
+// or expanded for use with the descriptor alone.
+func ExampleDscType_Where() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j, str := range []string{"Alpha", "Bravo", "Apple", "Avocado", "Axe"} {
+			db.Insert(&recType{Str: str, Num: int64(j + 1)})
+		}
+		tail := glRecDsc.Where("num > ?", 1).And("str LIKE ?", "A%").OrderBy("str").Limit(2)
+		tailStr, args, _ := tail.Tail()
+		fmt.Println(tailStr, args)
+		var rec recType
+		db.Query(&rec, tail)
+		for db.Next() {
+			fmt.Println(rec.Str, rec.Num)
+		}
+		db.QueryRow(&rec, tail.Offset(1))
+		fmt.Println(rec.Str)
+		pgTail := glRecDsc.WithDialect(dbmap.PostgresDialect{}).Where("num > ?", 1).Or("str = ?", "x")
+		fmt.Println(pgTail.Tail())
+		db.Query(&rec, glRecDsc.Where("num > ? AND num < ?", 1))
+		fmt.Println(db.Err())
+		db.ClearError()
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// WHERE (num > ?) AND (str LIKE ?) ORDER BY str LIMIT 2 [1 A%]
+	// Apple 3
+	// Avocado 4
+	// Avocado
+	// WHERE (num > $1) OR (str = $2) [1 x] <nil>
+	// dbmap query rec: condition "num > ? AND num < ?" has 2 placeholders but 1 parameters
+}
+
+// This example demonstrates a batch upsert. New records are inserted and
+// records that match existing ones in the conflict column update them, all in
+// a single command.
+func ExampleWrapType_UpsertBatch() {
+	type stockType struct {
+		ID  int64  `db_primary:"*" db_table:"stock"`
+		SKU string `db:"sku" db_unique:"*"`
+		Qty int64  `db:"qty"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(stockType{})
+		fmt.Println(dsc.UpsertMultiStr(2, []string{"sku"}, nil))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&stockType{SKU: "a-1", Qty: 5})
+		db.Insert(&stockType{SKU: "b-2", Qty: 8})
+		db.UpsertBatch([]stockType{{SKU: "b-2", Qty: 3}, {SKU: "c-3", Qty: 1}}, []string{"sku"})
+		var rec stockType
+		db.Query(&rec, "ORDER BY rowid")
+		for db.Next() {
+			fmt.Println(rec.ID, rec.SKU, rec.Qty)
+		}
+		db.UpsertBatch([]stockType{{SKU: "a-1"}}, []string{"qty"})
+		fmt.Println(db.Err())
+		db.ClearError()
+		_, err = hnd.Exec("CREATE TRIGGER stock_check BEFORE INSERT ON stock " +
+			"WHEN NEW.qty < 0 BEGIN SELECT RAISE(ABORT, 'negative quantity'); END")
+		if err == nil {
+			db.UpsertBatch([]stockType{{SKU: "d-4", Qty: 2}, {SKU: "e-5", Qty: -1}}, []string{"sku"})
+			fmt.Println(db.Err())
+			db.ClearError()
+			fmt.Println(db.Count(""))
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// INSERT INTO stock (sku, qty) VALUES (?, ?), (?, ?) ON CONFLICT (sku) DO UPDATE SET qty = excluded.qty;
+	// 1 a-1 5
+	// 2 b-2 3
+	// 3 c-3 1
+	// dbmap insert into stock: conflict columns (qty) are not those of a unique index
+	// dbmap insert into stock: batch records 0 to 1: negative quantity
+	// 3
+}
+
+// shortDialect is SQLite with a limit on the length of identifiers.
+type shortDialect struct {
+	dbmap.SQLiteDialect
+}
+
+// MaxIdentLength overrides the SQLite value.
+func (shortDialect) MaxIdentLength() int {
+	return 24
+}
+
+// This example demonstrates the shortening of index names that exceed the
+// dialect's limit on identifier length. A hash of the full name keeps the
+// shortened names unique and the same from run to run.
+func ExampleDscType_Indexes() {
+	type longType struct {
+		ID    int64  `db_primary:"*" db_table:"warehouse_inventory"`
+		Shelf string `db:"shelf" db_index:"location1"`
+		Bin   string `db:"bin" db_index:"location2, bin1"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(longType{})
+		fmt.Println(dsc.Indexes())
+		dsc = dsc.WithDialect(shortDialect{})
+		for _, nameStr := range dsc.Indexes() {
+			fmt.Println(nameStr, len(nameStr))
+		}
+		db := dsc.Wrap(hnd)
+		db.Create()
+		fmt.Println(db.IndexDrift())
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// [warehouse_inventory_bin warehouse_inventory_location]
+	// warehouse_inven_c5b75d63 24
+	// warehouse_inventory_bin 23
+	// [] [] <nil>
+}
+
+// baseType holds fields common to several record types.
+type baseType struct {
+	ID      int64 `db_primary:"*"`
+	Version int64 `db:"version"`
+}
+
+// This example demonstrates the use of an embedded structure. Its tagged
+// fields, including the primary key, are treated as fields of the outer
+// structure.
+func ExampleDescribe_embedded() {
+	type petType struct {
+		baseType
+		Name string `db:"name" db_table:"pet"`
+	}
+	type dupType struct {
+		baseType
+		Version string `db:"version" db_table:"dup"`
+	}
+	type ptrType struct {
+		*baseType
+		Name string `db:"name" db_table:"ptr"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(petType{})
+		fmt.Println(dsc.SelectStr(""))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		rec := petType{Name: "Rex"}
+		rec.Version = 1
+		db.Insert(&rec)
+		rec.Version++
+		db.Update(&rec)
+		var get petType
+		db.QueryRow(&get, "WHERE rowid = ?", rec.ID)
+		fmt.Println(get.ID, get.Version, get.Name)
+		_, err = dbmap.Describe(dupType{})
+		fmt.Println(err)
+		_, err = dbmap.Describe(ptrType{})
+		fmt.Println(err)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// SELECT rowid, version, name FROM pet;
+	// 1 2 Rex
+	// column "version" is used by both field baseType.Version and field Version
+	// embedded structure pointer *dbmap_test.baseType has tagged fields and is not supported; embed the structure itself
+}
+
+// This example demonstrates column type overrides in "db" tags. Only the
+// declaration of the column is affected; values are bound according to the
+// type of the field.
+func ExampleDescribe_typeOverride() {
+	type userType struct {
+		ID    int64   `db_primary:"*" db_table:"member"`
+		Email string  `db:"email, varchar(255)"`
+		Name  string  `db:"name,text COLLATE NOCASE"`
+		Score float64 `db:"score,numeric(8, 2)"`
+	}
+	type badType struct {
+		Num int64 `db:"num,text" db_table:"bad"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(userType{})
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		db := dsc.Wrap(hnd)
+		db.Create()
+		rec := userType{Email: "athos@example.com", Name: "Athos", Score: 12.5}
+		db.Insert(&rec)
+		var get userType
+		db.QueryRow(&get, "WHERE name = ?", "ATHOS")
+		fmt.Println(get.Email, get.Name, get.Score)
+		_, err = dbmap.Describe(badType{})
+		fmt.Println(err)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE member (email varchar(255), name text COLLATE NOCASE, score numeric(8, 2));
+	// athos@example.com Athos 12.5
+	// field Num: column type override text conflicts with column type integer
+}
+
+// This example demonstrates testing for the presence of matching records
+// without retrieving them.
+func ExampleWrapType_Exists() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var j int64
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		fmt.Println(db.Exists(""))
+		for j = 1; j <= 5; j++ {
+			db.Insert(&recType{Str: hashStr(j), Num: j})
+		}
+		fmt.Println(glRecDsc.ExistsStr("WHERE num > ?"))
+		fmt.Println(db.Exists("WHERE num > ?", 4))
+		fmt.Println(db.Exists("WHERE num > ?", 5))
+		fmt.Println(db.Exists("WHERE bogus > ?", 5), db.OK())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// false
+	// SELECT EXISTS(SELECT 1 FROM rec WHERE num > ?);
+	// true
+	// false
+	// false false
+}
+
+// This example demonstrates the retrieval of a hand-written query with two
+// computed columns that the record structure does not have. The known columns
+// are bound by name, in any order, and the computed ones are captured by the
+// hook.
+func ExampleWrapType_QueryExtra() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j, str := range []string{"Athos", "Porthos", "Aramis"} {
+			db.Insert(&recType{Str: str, Num: int64(j + 1)})
+		}
+		var rec recType
+		var twice int64
+		var label string
+		db.QueryExtra(&rec, func(extra []interface{}) {
+			extra[0] = &twice
+			extra[1] = &label
+		}, "SELECT num, num * 2 AS twice, str, 'rec-' || rowid AS label FROM rec ORDER BY num")
+		for db.Next() {
+			fmt.Println(rec.Num, rec.Str, twice, label)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 1 Athos 2 rec-1
+	// 2 Porthos 4 rec-2
+	// 3 Aramis 6 rec-3
+}
+
+// This example demonstrates compare-and-swap updates of a status column. The
+// second swap fails because the status no longer holds the expected value.
+func ExampleWrapType_CompareAndSwap() {
+	type jobType struct {
+		ID     int64  `db_primary:"*" db_table:"job"`
+		Name   string `db:"name"`
+		Status string `db:"status"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(jobType{})
+		fmt.Println(dsc.CompareAndSwapStr("status"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		job := jobType{Name: "backup", Status: "queued"}
+		db.Insert(&job)
+		fmt.Println(db.CompareAndSwap(&job, "status", "queued", "running"), job.Status)
+		fmt.Println(db.CompareAndSwap(&job, "status", "queued", "running"), job.Status)
+		var get jobType
+		db.QueryRow(&get, "WHERE rowid = ?", job.ID)
+		fmt.Println(get.Status)
+		fmt.Println(db.CompareAndSwap(&job, "bogus", "running", "done"), db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// UPDATE job SET status = ? WHERE rowid = ? AND status = ?;
+	// true running
+	// false running
+	// running
+	// false dbmap update job: field name "bogus" not in structure
+}
+
+// This example demonstrates the collection of all retrieved records in a
+// slice.
+func ExampleWrapType_QueryAll() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j, str := range []string{"Athos", "Porthos", "Aramis"} {
+			db.Insert(&recType{Str: str, Num: int64(j + 1)})
+		}
+		var list []recType
+		db.QueryAll(&list, "WHERE num > ?", 5)
+		fmt.Println(len(list), list == nil)
+		db.QueryAll(&list, "ORDER BY str")
+		for _, rec := range list {
+			fmt.Println(rec.ID, rec.Str, rec.Num)
+		}
+		var bad []int
+		db.QueryAll(&bad, "")
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 0 true
+	// 3 Aramis 3
+	// 1 Athos 1
+	// 2 Porthos 2
+	// dbmap query rec: passed-in value must be a pointer to a slice of dbmap_test.recType
+}
+
+// This example demonstrates repeated updates and deletions, which reuse the
+// statements that the wrapper has prepared, both outside and within a
+// transaction. Close() releases the statements when the wrapper is no longer
+// needed.
+func ExampleWrapType_Close() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var j int64
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		list := make([]recType, 6)
+		for j = 0; j < 6; j++ {
+			list[j] = recType{Str: hashStr(j), Num: j}
+			db.Insert(&list[j])
+		}
+		for j = 0; j < 3; j++ {
+			list[j].Num += 10
+			db.Update(&list[j], "num")
+		}
+		db.TransactionBegin()
+		for j = 3; j < 6; j++ {
+			list[j].Num += 20
+			db.Update(&list[j], "num")
+		}
+		db.Delete("WHERE num = ?", 23)
+		db.TransactionEnd()
+		db.Update(&list[0], "num")
+		db.Delete("WHERE num = ?", 24)
+		db.Close()
+		var rec recType
+		db.Query(&rec, "ORDER BY num")
+		for db.Next() {
+			fmt.Println(rec.Num)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 10
+	// 11
+	// 12
+	// 25
+}
+
+// This example demonstrates a field whose zero value is stored as the
+// sentinel -1.
+func ExampleDescribe_zero() {
+	type nodeType struct {
+		ID     int64  `db_primary:"*" db_table:"node"`
+		Name   string `db:"name"`
+		Parent int    `db:"parent" db_zero:"-1"`
+	}
+	type badType struct {
+		Flag bool `db:"flag" db_zero:"1" db_table:"bad"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(nodeType{}).Wrap(hnd)
+		db.Create()
+		db.Insert(&nodeType{Name: "root"})
+		db.Insert(&nodeType{Name: "leaf", Parent: 1})
+		var parent int
+		err = hnd.QueryRow("SELECT parent FROM node WHERE name = 'root'").Scan(&parent)
+		fmt.Println(parent, err)
+		var rec nodeType
+		db.Query(&rec, "ORDER BY rowid")
+		for db.Next() {
+			fmt.Println(rec.Name, rec.Parent)
+		}
+		_, err = dbmap.Describe(badType{})
+		fmt.Println(err)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// -1 <nil>
+	// root 0
+	// leaf 1
+	// field Flag: type bool is not a number or string and cannot be tagged "db_zero"
+}
+
+// This example demonstrates the retrieval of authors and their titles with
+// two queries. The titles are then attached to their authors.
+func ExampleStitchHasMany() {
+	type titleType struct {
+		ID       int64  `db_primary:"*" db_table:"title"`
+		AuthorID int64  `db:"author_id"`
+		Name     string `db:"name"`
+	}
+	type authorType struct {
+		ID     int64  `db_primary:"*" db_table:"author"`
+		Name   string `db:"name"`
+		Titles []titleType
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		aDb := dbmap.MustDescribe(authorType{}).Wrap(hnd)
+		tDb := dbmap.MustDescribe(titleType{}).WrapJoin(aDb)
+		aDb.Create()
+		tDb.Create()
+		for _, str := range []string{"William Faulkner/Go Down, Moses/The Bear",
+			"Mark Twain/Huckleberry Finn/Life on the Mississippi", "Harper Lee"} {
+			list := strings.Split(str, "/")
+			author := authorType{Name: list[0]}
+			aDb.Insert(&author)
+			for _, nameStr := range list[1:] {
+				tDb.Insert(&titleType{AuthorID: author.ID, Name: nameStr})
+			}
+		}
+		var authorList []authorType
+		var titleList []titleType
+		aDb.QueryAll(&authorList, "ORDER BY name")
+		tDb.QueryAll(&titleList, "ORDER BY name")
+		aDb.SetError(dbmap.StitchHasMany(&authorList, titleList, "rowid", "author_id"))
+		for _, author := range authorList {
+			fmt.Println(author.Name, len(author.Titles))
+			for _, title := range author.Titles {
+				fmt.Println("  " + title.Name)
+			}
+		}
+		fmt.Println(dbmap.StitchHasMany(&authorList, titleList, "rowid", "name"))
+		hnd.Close()
+		err = aDb.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// Harper Lee 0
+	// Mark Twain 2
+	//   Huckleberry Finn
+	//   Life on the Mississippi
+	// William Faulkner 2
+	//   Go Down, Moses
+	//   The Bear
+	// parent key "rowid" of type int64 does not match foreign key "name" of type string
+}
+
+// This example demonstrates the removal of a record identified by its primary
+// key.
+func ExampleWrapType_DeleteRec() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		list := []recType{{Str: "Athos", Num: 1}, {Str: "Porthos", Num: 2}, {Str: "Aramis", Num: 3}}
+		for j := range list {
+			db.Insert(&list[j])
+		}
+		cmdStr, args, _ := glRecDsc.DeleteRecArg(list[1])
+		fmt.Println(cmdStr, args)
+		db.DeleteRec(list[1])
+		count, _ := db.Result().RowsAffected()
+		fmt.Println(count, db.Count(""))
+		db.DeleteRec(&list[1])
+		count, _ = db.Result().RowsAffected()
+		fmt.Println(count, db.Count(""))
+		type noKeyType struct {
+			Str string `db:"str" db_table:"rec"`
+		}
+		nkDb := dbmap.MustDescribe(noKeyType{}).Wrap(hnd)
+		nkDb.DeleteRec(noKeyType{Str: "Athos"})
+		fmt.Println(nkDb.Err())
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// DELETE FROM rec WHERE rowid = ?; [2]
+	// 1 2
+	// 0 2
+	// dbmap delete from rec: deletion of a record requires structure with primary ID
+}
+
+// This example demonstrates the retrieval of records one page at a time.
+func ExampleWrapType_QueryPage() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		var j int64
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j = 1; j <= 7; j++ {
+			db.Insert(&recType{Str: hashStr(j), Num: j})
+		}
+		var rec recType
+		for _, page := range []int{0, 2, 3, 4} {
+			var list []int64
+			db.QueryPage(&rec, "WHERE num > ? ORDER BY num", page, 2, 1)
+			for db.Next() {
+				list = append(list, rec.Num)
+			}
+			fmt.Println(page, list)
+		}
+		db.QueryPage(&rec, "ORDER BY num", 1, 0)
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 0 [2 3]
+	// 2 [4 5]
+	// 3 [6 7]
+	// 4 []
+	// dbmap query rec: page size 0 is less than one
+}
+
+// This example demonstrates insertions before, within and after a
+// transaction without calls to InsertClear(). The statement prepared for the
+// transaction is released when the transaction ends.
+func ExampleWrapType_TransactionEnd() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&recType{Str: "Athos", Num: 1})
+		db.TransactionBegin()
+		db.Insert(&recType{Str: "Porthos", Num: 2})
+		db.Insert(&recType{Str: "Aramis", Num: 3})
+		db.TransactionEnd()
+		db.Insert(&recType{Str: "d'Artagnan", Num: 4})
+		db.TransactionBegin()
+		db.Insert(&recType{Str: "Rochefort", Num: 5})
+		db.TransactionRollback()
+		db.Insert(&recType{Str: "Planchet", Num: 6})
+		fmt.Println(db.Count(""), db.Err())
+		db.Close()
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 5 <nil>
+}
+
+// This example demonstrates a scalar computed over groups of records, here
+// the number of num values that occur more than once.
+func ExampleWrapType_ScalarGrouped() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j, num := range []int64{1, 2, 2, 3, 3, 3, 4, 5, 5} {
+			db.Insert(&recType{Str: hashStr(int64(j)), Num: num})
+		}
+		fmt.Println(glRecDsc.ScalarGroupedStr("COUNT(*)", "num", "COUNT(*) > ?", ""))
+		var count, max int64
+		db.ScalarGrouped(&count, "COUNT(*)", "num", "COUNT(*) > ?", "", 1)
+		fmt.Println(count)
+		db.ScalarGrouped(&count, "COUNT(*)", "num", "COUNT(*) > ?", "WHERE num < ?", 4, 1)
+		fmt.Println(count)
+		db.ScalarGrouped(&max, "MAX(num)", "num", "", "")
+		fmt.Println(max)
+		db.ScalarGrouped(&count, "COUNT(*)", "num", "COUNT(*) > ?", "")
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// SELECT COUNT(*) FROM (SELECT num FROM rec GROUP BY num HAVING COUNT(*) > ?) AS grp;
+	// 3
+	// 2
+	// 5
+	// dbmap query rec: grouped query has 1 placeholders but 0 parameters
+}
+
+// This example demonstrates a wrapper that is shared by goroutines that insert
+// records concurrently. It is suitable for the race detector.
+func ExampleDscType_WrapSafe() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		// Serialize writes to the database file
+		hnd.SetMaxOpenConns(1)
+		db := glRecDsc.WrapSafe(hnd)
+		w := db.Wrap()
+		w.Create()
+		err = w.Err()
+		if err == nil {
+			var wg sync.WaitGroup
+			errList := make([]error, 16)
+			for j := range errList {
+				wg.Add(1)
+				go func(j int) {
+					defer wg.Done()
+					for k := 0; k < 10 && errList[j] == nil; k++ {
+						rec := recType{Str: hashStr(int64(j)), Num: int64(j*10 + k)}
+						errList[j] = db.Insert(&rec)
+						if errList[j] == nil && rec.ID == 0 {
+							errList[j] = fmt.Errorf("record %d not assigned an identifier", rec.Num)
+						}
+					}
+				}(j)
+			}
+			wg.Wait()
+			for _, e := range errList {
+				if err == nil {
+					err = e
+				}
+			}
+		}
+		if err == nil {
+			fmt.Println(db.Count(""))
+			fmt.Println(db.Count("WHERE num < ?", 10))
+			var rec recType
+			fmt.Println(db.QueryRow(&rec, "WHERE num = ?", 1000))
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 160 <nil>
+	// 10 <nil>
+	// dbmap query rec: sql: no rows in result set
+}
+
+// This example demonstrates a logger that reports each command submitted to
+// the database. The error of the last query is reported before it is
+// retained by the wrapper.
+func ExampleWrapType_SetLogger() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.SetLogger(func(cmdStr string, args []interface{}, dur time.Duration, err error) {
+			if strings.HasPrefix(cmdStr, "CREATE") {
+				cmdStr = cmdStr[:strings.Index(cmdStr, " (")]
+			}
+			fmt.Println(cmdStr, args, dur >= 0, err != nil)
+		})
+		db.Create()
+		db.Insert(&recType{Str: "Athos", Num: 1})
+		fmt.Println(db.Count("WHERE num > ?", 0))
+		db.Count("WHERE bogus > ?", 0)
+		db.SetLogger(nil)
+		db.ClearError()
+		fmt.Println(db.Count(""))
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE rec [] true false
+	// CREATE INDEX rec_num ON rec [] true false
+	// CREATE INDEX rec_str ON rec [] true false
+	// INSERT INTO rec (str, num) VALUES (?, ?); [] true false
+	// INSERT INTO rec (str, num) VALUES (?, ?); [Athos 1] true false
+	// SELECT COUNT(*) FROM rec WHERE num > ?; [0] true false
+	// 1
+	// SELECT COUNT(*) FROM rec WHERE bogus > ?; [0] true true
+	// 1
+}
+
+// sqlServerDialect is an outline of a dialect for Microsoft SQL Server. It
+// embeds SQLiteDialect for the methods that it does not override.
+type sqlServerDialect struct {
+	dbmap.SQLiteDialect
+}
+
+// PlaceholderMark implements dbmap.Dialect.
+func (sqlServerDialect) PlaceholderMark(i int) string {
+	return fmt.Sprintf("@p%d", i)
+}
+
+// PrimaryKeyColumn implements dbmap.Dialect.
+func (sqlServerDialect) PrimaryKeyColumn() string {
+	return "id"
+}
+
+// AutoIncrementType implements dbmap.Dialect.
+func (sqlServerDialect) AutoIncrementType() string {
+	return "BIGINT IDENTITY(1, 1) PRIMARY KEY"
+}
+
+// ColumnType implements dbmap.Dialect.
+func (sqlServerDialect) ColumnType(typeStr string) string {
+	return map[string]string{"integer": "bigint", "real": "float", "text": "nvarchar(max)",
+		"blob": "varbinary(max)", "datetime": "datetimeoffset"}[typeStr]
+}
+
+// QuoteIdent implements dbmap.Dialect.
+func (sqlServerDialect) QuoteIdent(str string) string {
+	return "[" + strings.Replace(str, "]", "]]", -1) + "]"
+}
+
+// UpsertClause implements dbmap.Dialect. SQL Server has no such clause; MERGE
+// is used instead.
+func (sqlServerDialect) UpsertClause(conflictCols, updateCols []string) string {
+	return ""
+}
+
+// LimitClause implements dbmap.Dialect. SQL Server requires an ORDER BY
+// clause before this one.
+func (sqlServerDialect) LimitClause(limitStr, offsetStr string) string {
+	if len(offsetStr) == 0 {
+		offsetStr = "0"
+	}
+	str := "OFFSET " + offsetStr + " ROWS"
+	if len(limitStr) > 0 {
+		str += " FETCH NEXT " + limitStr + " ROWS ONLY"
+	}
+	return str
+}
+
+// RandomFunc implements dbmap.Dialect.
+func (sqlServerDialect) RandomFunc() string {
+	return "NEWID()"
+}
+
+// This example demonstrates the generation of commands for a database that is
+// not supported by this package by means of an application-defined dialect.
+func ExampleDialect() {
+	type itemType struct {
+		ID      int64  `db_primary:"*" db_table:"item"`
+		Name    string `db:"name"`
+		Deleted bool   `db:"deleted" db_softdelete:"*"`
+	}
+	dsc := dbmap.MustDescribe(itemType{}).WithDialect(sqlServerDialect{})
+	createStr, _ := dsc.CreateStr()
+	fmt.Println(createStr)
+	fmt.Println(dsc.InsertStr())
+	tailStr, args, _ := dsc.Where("name LIKE ?", "A%").OrderBy("name").Limit(10).Offset(20).Tail()
+	fmt.Println(dsc.SelectWithDeletedStr(tailStr), args)
+	tailStr, _, _ = dsc.Tail().OrderByRandom().Limit(1).Tail()
+	fmt.Println(dsc.SelectWithDeletedStr(tailStr))
+	cmdStr, args := dsc.SoftDeleteArg("WHERE name = @p1", "Athos")
+	fmt.Println(cmdStr, args)
+	// Output:
+	// CREATE TABLE item (id BIGINT IDENTITY(1, 1) PRIMARY KEY, name nvarchar(max), deleted bigint);
+	// INSERT INTO item (name, deleted) VALUES (@p1, @p2);
+	// SELECT id, name, deleted FROM item WHERE name LIKE @p1 ORDER BY name OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY; [A%]
+	// SELECT id, name, deleted FROM item ORDER BY NEWID() OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY;
+	// UPDATE item SET deleted = 1 WHERE name = @p1; [Athos]
+}
+
+// This example demonstrates that a uint64 value is retrieved exactly if it
+// can be stored as a signed integer and is otherwise rejected.
+func ExampleDscType_InsertArg() {
+	type counterType struct {
+		ID    int64  `db_primary:"*" db_table:"counter"`
+		Count uint64 `db:"count"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(counterType{}).Wrap(hnd)
+		db.Create()
+		rec := counterType{Count: math.MaxInt64}
+		db.Insert(&rec)
+		var get counterType
+		db.QueryRow(&get, "WHERE rowid = ?", rec.ID)
+		fmt.Println(get.Count == math.MaxInt64, db.Err())
+		db.Insert(&counterType{Count: math.MaxUint64})
+		fmt.Println(db.Err())
+		db.ClearError()
+		fmt.Println(db.Count(""))
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// true <nil>
+	// dbmap insert into counter: value 18446744073709551615 of field "count" exceeds the largest integer that can be stored, 9223372036854775807
+	// 1
+}
+
+// This example demonstrates fields that are stored as JSON text.
+func ExampleDescribe_json() {
+	type sizeType struct {
+		Width  int `json:"w"`
+		Height int `json:"h"`
+	}
+	type boxType struct {
+		ID     int64          `db_primary:"*" db_table:"box"`
+		Name   string         `db:"name"`
+		Counts map[string]int `db:"counts" db_json:"*"`
+		Size   sizeType       `db:"size" db_json:"*"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(boxType{})
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		db := dsc.Wrap(hnd)
+		db.Create()
+		rec := boxType{Name: "crate", Counts: map[string]int{"apple": 12, "pear": 3},
+			Size: sizeType{Width: 40, Height: 25}}
+		db.Insert(&rec)
+		db.Insert(&boxType{Name: "empty"})
+		var str string
+		err = hnd.QueryRow("SELECT counts || ' ' || size FROM box WHERE name = 'crate'").Scan(&str)
+		fmt.Println(str, err)
+		var get boxType
+		db.Query(&get, "ORDER BY rowid")
+		for db.Next() {
+			fmt.Println(get.Name, get.Counts, get.Counts == nil, get.Size)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE box (name text, counts text, size text);
+	// {"apple":12,"pear":3} {"w":40,"h":25} <nil>
+	// crate map[apple:12 pear:3] false {40 25}
+	// empty map[] true {0 0}
+}
+
+// This example demonstrates that table and column names that are reserved
+// words are quoted in generated commands.
+func ExampleDescribe_reserved() {
+	type groupType struct {
+		ID    int64  `db_primary:"*" db_table:"group"`
+		Name  string `db:"name"`
+		Order int64  `db:"order" db_index:"order1"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(groupType{})
+		createStr, idxList := dsc.CreateStr()
+		fmt.Println(createStr)
+		fmt.Println(idxList)
+		fmt.Println(dsc.InsertStr())
+		fmt.Println(dsc.SelectStr("ORDER BY rowid"))
+		fmt.Println(dsc.UpdateStr("order"))
+		fmt.Println(dsc.TruncateStr())
+		db := dsc.Wrap(hnd)
+		db.Create()
+		rec := groupType{Name: "alpha", Order: 2}
+		db.Insert(&rec)
+		db.Insert(&groupType{Name: "beta", Order: 1})
+		rec.Order = 3
+		db.Update(&rec, "order")
+		var get groupType
+		db.Query(&get, "ORDER BY \"order\"")
+		for db.Next() {
+			fmt.Println(get.Name, get.Order)
+		}
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE "group" (name text, "order" integer);
+	// [CREATE INDEX group_order ON "group" ("order")]
+	// INSERT INTO "group" (name, "order") VALUES (?, ?);
+	// SELECT rowid, name, "order" FROM "group" ORDER BY rowid;
+	// UPDATE "group" SET "order" = ? WHERE rowid = ?;
+	// DELETE FROM "group";
+	// beta 1
+	// alpha 3
+}
+
+// This example demonstrates a foreign key constraint declared with a "db_fk"
+// tag. Since SQLite enforces such constraints for a single connection only,
+// the handle is limited to one.
+func ExampleWrapType_EnableForeignKeys() {
+	type authorType struct {
+		Code string `db:"code" db_primary:"*" db_table:"author"`
+		Name string `db:"name"`
+	}
+	type bookType struct {
+		ID         int64  `db_primary:"*" db_table:"book"`
+		AuthorCode string `db:"author_code" db_fk:"author(code) cascade"`
+		Title      string `db:"title"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		hnd.SetMaxOpenConns(1)
+		authorDsc := dbmap.MustDescribe(authorType{})
+		bookDsc := dbmap.MustDescribe(bookType{})
+		createStr, _ := bookDsc.CreateStr()
+		fmt.Println(createStr)
+		authorDb := authorDsc.Wrap(hnd)
+		authorDb.EnableForeignKeys()
+		authorDb.Create()
+		authorDb.Insert(authorType{Code: "dumas", Name: "Alexandre Dumas"})
+		bookDb := bookDsc.Wrap(hnd)
+		bookDb.Create()
+		bookDb.Insert(&bookType{AuthorCode: "dumas", Title: "The Three Musketeers"})
+		bookDb.Insert(&bookType{AuthorCode: "hugo", Title: "Les Misérables"})
+		fmt.Println(bookDb.OK())
+		bookDb.ClearError()
+		fmt.Println(bookDb.Count(""))
+		authorDb.Delete("WHERE code = ?", "dumas")
+		fmt.Println(bookDb.Count(""))
+		hnd.Close()
+		err = authorDb.Err()
+		if err == nil {
+			err = bookDb.Err()
+		}
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE book (author_code text, title text, FOREIGN KEY (author_code) REFERENCES author (code) ON DELETE CASCADE);
+	// false
+	// 1
+	// 0
+}
+
+// This example demonstrates the retrieval of records into a map keyed by
+// their primary key.
+func ExampleWrapType_QueryMap() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"alpha", "beta", "gamma"} {
+			db.Insert(&recType{Str: str, Num: int64(len(str))})
+		}
+		var recMap map[int64]recType
+		db.QueryMap(&recMap, "WHERE num > ?", 0)
+		fmt.Println(len(recMap), recMap[2].Str)
+		fmt.Println(recMap)
+		var strMap map[string]recType
+		db.QueryMap(&strMap, "")
+		fmt.Println(db.Err())
+		hnd.Close()
+		err = nil
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 3 beta
+	// map[1:{1 alpha 5} 2:{2 beta 4} 3:{3 gamma 5}]
+	// dbmap query rec: passed-in value must be a pointer to a map of dbmap_test.recType keyed by int64
+}
+
+// This example demonstrates the use of a savepoint to undo part of a
+// transaction without ending it.
+func ExampleWrapType_SavepointRollback() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.SavepointBegin("outside")
+		fmt.Println(db.Err())
+		db.ClearError()
+		db.TransactionBegin()
+		db.Insert(&recType{Str: "Athos", Num: 1})
+		db.SavepointBegin("sp1")
+		db.Insert(&recType{Str: "Porthos", Num: 2})
+		fmt.Println(db.Count(""))
+		db.SavepointRollback("sp1")
+		fmt.Println(db.Count(""))
+		db.Insert(&recType{Str: "Aramis", Num: 3})
+		db.SavepointRelease("sp1")
+		db.TransactionCommit()
+		var list []recType
+		db.QueryAll(&list, "ORDER BY rowid")
+		for _, rec := range list {
+			fmt.Println(rec.Str, rec.Num)
+		}
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// SAVEPOINT outside requires an active transaction
+	// 2
+	// 1
+	// Athos 1
+	// Aramis 3
+	// <nil>
+}
+
+// This example demonstrates a primary key held in a named column rather than
+// in rowid.
+func ExampleDescribe_primaryColumn() {
+	type itemType struct {
+		ID   int64  `db_primary:"item_id" db_table:"item"`
+		Name string `db:"name"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(itemType{})
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		fmt.Println(dsc.SelectStr("ORDER BY item_id"))
+		fmt.Println(dsc.UpdateStr("name"))
+		fmt.Println(dsc.WithDialect(dbmap.PostgresDialect{}).InsertStr())
+		db := dsc.Wrap(hnd)
+		db.Create()
+		rec := itemType{Name: "anvil"}
+		db.Insert(&rec)
+		db.Insert(&itemType{Name: "bellows"})
+		rec.Name = "hammer"
+		db.Update(&rec, "name")
+		var list []itemType
+		db.QueryAll(&list, "ORDER BY item_id")
+		fmt.Println(list)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE item (item_id integer PRIMARY KEY, name text);
+	// SELECT item_id, name FROM item ORDER BY item_id;
+	// UPDATE item SET name = ? WHERE item_id = ?;
+	// INSERT INTO item (name) VALUES ($1) RETURNING item_id;
+	// [{1 hammer} {2 bellows}]
+}
+
+// This example demonstrates the update of a slice of records with a single
+// prepared command. An element that cannot be updated is identified by a
+// *BatchError.
+func ExampleWrapType_UpdateAll() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"Athos", "Porthos", "Aramis"} {
+			db.Insert(&recType{Str: str, Num: int64(len(str))})
+		}
+		var list []recType
+		db.QueryAll(&list, "ORDER BY rowid")
+		for j := range list {
+			list[j].Num *= 10
+		}
+		db.TransactionBegin()
+		count := db.UpdateAll(list, "num")
+		fmt.Println(count, db.TransactionRowsAffected())
+		db.TransactionEnd()
+		var get []recType
+		db.QueryAll(&get, "ORDER BY rowid")
+		fmt.Println(get)
+		db.UpdateAll(get[0], "num")
+		fmt.Println(db.Err())
+		db.ClearError()
+		db.UpdateAll([]interface{}{get[0], "Aramis"}, "num")
+		var batchErr *dbmap.BatchError
+		fmt.Println(errors.As(db.Err(), &batchErr) && batchErr.Index == 1, db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 3 3
+	// [{1 Athos 50} {2 Porthos 70} {3 Aramis 60}]
+	// dbmap update rec: passed-in value must be a slice of dbmap_test.recType
+	// true dbmap update rec: batch record 1: value passed into update must be a structure (or pointer to a structure) of type dbmap_test.recType
+}
+
+// This example demonstrates the retrieval of values assigned by the database,
+// here by a trigger, when a record is inserted.
+func ExampleWrapType_InsertReturning() {
+	type taskType struct {
+		ID     int64  `db_primary:"*" db_table:"task"`
+		Name   string `db:"name"`
+		Status string `db:"status"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(taskType{})
+		fmt.Println(dsc.WithDialect(dbmap.PostgresDialect{}).InsertReturningStr([]string{"status"}))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		_, err = hnd.Exec("CREATE TRIGGER task_status AFTER INSERT ON task " +
+			"BEGIN UPDATE task SET status = 'queued' WHERE rowid = new.rowid; END")
+		db.SetError(err)
+		rec := taskType{Name: "sweep", Status: "open"}
+		db.InsertReturning(&rec, "status")
+		fmt.Println(rec.ID, rec.Name, rec.Status)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// INSERT INTO task (name, status) VALUES ($1, $2) RETURNING id, status;
+	// 1 sweep queued
+}
+
+// This example demonstrates the summary of a descriptor that is used to
+// verify the interpretation of structure tags.
+func ExampleDscType_Schema() {
+	type memberType struct {
+		ID    int64  `db_primary:"*" db_table:"member"`
+		Email string `db:"email" db_unique:"email1 NOCASE" db_notnull:"*"`
+		Group string `db:"group" db_index:"group1"`
+		Name  string `db:"name" db_index:"group2, name1"`
+	}
+	type tagType struct {
+		Label string `db:"label" db_table:"tag"`
+	}
+	fmt.Println(dbmap.MustDescribe(memberType{}).Schema())
+	fmt.Println(dbmap.MustDescribe(tagType{}).Schema())
+	// Output:
+	// table member
+	//   rowid primary key [ID int64]
+	//   email text NOT NULL [Email string]
+	//   "group" text [Group string]
+	//   name text [Name string]
+	//   unique index member_email ON member (email COLLATE NOCASE)
+	//   index member_group ON member ("group", name)
+	//   index member_name ON member (name)
+	// table tag
+	//   label text [Label string]
+}
+
+// This example demonstrates the retrieval of the commands that create a
+// table's indexes. The names and commands are in the same sorted order.
+func ExampleDscType_IndexStatements() {
+	type memberType struct {
+		ID    int64  `db_primary:"*" db_table:"member"`
+		Email string `db:"email" db_unique:"email1"`
+		Group string `db:"group" db_index:"group1"`
+		Name  string `db:"name" db_index:"group2, name1"`
+	}
+	dsc := dbmap.MustDescribe(memberType{})
+	nameList := dsc.Indexes()
+	for j, cmdStr := range dsc.IndexStatements() {
+		fmt.Printf("%s: %s\n", nameList[j], cmdStr)
+	}
+	// Output:
+	// member_email: CREATE UNIQUE INDEX member_email ON member (email)
+	// member_group: CREATE INDEX member_group ON member ("group", name)
+	// member_name: CREATE INDEX member_name ON member (name)
+}
+
+// This example demonstrates that the index commands returned by CreateStr()
+// are sorted by index name, and that the columns of each index follow the
+// sequence numbers of its tags rather than the order of the fields.
+func ExampleDscType_30() {
+	type placeType struct {
+		ID      int64  `db_primary:"*" db_table:"place"`
+		Zip     string `db:"zip" db_index:"zip1, loc3"`
+		City    string `db:"city" db_index:"loc2"`
+		Country string `db:"country" db_index:"loc1" db_unique:"code2"`
+		Code    string `db:"code" db_unique:"code1"`
+	}
+	for j := 0; j < 3; j++ {
+		_, idxList := dbmap.MustDescribe(placeType{}).CreateStr()
+		fmt.Println(strings.Join(idxList, "\n"))
+	}
+	// Output:
+	// CREATE UNIQUE INDEX place_code ON place (code, country)
+	// CREATE INDEX place_loc ON place (country, city, zip)
+	// CREATE INDEX place_zip ON place (zip)
+	// CREATE UNIQUE INDEX place_code ON place (code, country)
+	// CREATE INDEX place_loc ON place (country, city, zip)
+	// CREATE INDEX place_zip ON place (zip)
+	// CREATE UNIQUE INDEX place_code ON place (code, country)
+	// CREATE INDEX place_loc ON place (country, city, zip)
+	// CREATE INDEX place_zip ON place (zip)
+}
+
+// This example demonstrates the column order of a composite index whose
+// sequence numbers have different numbers of digits. The sequences are
+// compared as integers, so the segment numbered 10 follows the one numbered 2.
+func ExampleDscType_31() {
+	type eventType struct {
+		ID    int64  `db_primary:"*" db_table:"event"`
+		Place string `db:"place" db_index:"when10"`
+		Day   string `db:"day" db_index:"when2"`
+		Year  int    `db:"year" db_index:"when1"`
+	}
+	_, idxList := dbmap.MustDescribe(eventType{}).CreateStr()
+	fmt.Println(strings.Join(idxList, "\n"))
+	// Output:
+	// CREATE INDEX event_when ON event (year, day, place)
+}
+
+// This example demonstrates the error retained when a query returns a number
+// of columns that differs from the number of fields in the record. Here, the
+// column name of a field mistakenly selects all columns of the table.
+func ExampleWrapType_Query() {
+	type wrongType struct {
+		ID  int64  `db_primary:"*" db_table:"rec"`
+		All string `db:"rec.*"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&recType{Str: "abc", Num: 1})
+		wrongDb := dbmap.MustDescribe(wrongType{}).WrapJoin(db)
+		var rec wrongType
+		wrongDb.Query(&rec, "")
+		for wrongDb.Next() {
+			fmt.Println(rec.All)
+		}
+		fmt.Println(wrongDb.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// dbmap query rec: query returns 3 columns but record expects 2
+}
+
+// This example demonstrates the retrieval of an aggregate query into a
+// structure that is unrelated to the table's record type.
+func ExampleWrapType_QueryInto() {
+	type countType struct {
+		Str   string `db:"str"`
+		Count int64  `db:"cnt"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j, str := range []string{"abc", "def", "abc", "ghi", "abc", "def"} {
+			db.Insert(&recType{Str: str, Num: int64(j)})
+		}
+		var list []countType
+		db.QueryInto(&list, "SELECT str, COUNT(*) AS cnt FROM rec GROUP BY str ORDER BY cnt DESC, str")
+		fmt.Println(list)
+		var top countType
+		db.QueryInto(&top, "SELECT str, MAX(num) AS cnt FROM rec WHERE str = ?", "def")
+		fmt.Println(top)
+		db.QueryInto(&top, "SELECT str, COUNT(*) AS total FROM rec")
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// [{abc 3} {def 2} {ghi 1}]
+	// {def 5}
+	// dbmap query rec: column "total" has no field in structure dbmap_test.countType
+}
+
+// This example demonstrates the selection of records whose column value is
+// one of a list that is not known until runtime.
+func ExampleIn() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j, str := range []string{"Athos", "Porthos", "Aramis", "d'Artagnan"} {
+			db.Insert(&recType{Str: str, Num: int64(j + 1)})
+		}
+		show := func(tailStr string, args []interface{}) {
+			var rec recType
+			fmt.Println(tailStr, args)
+			db.Query(&rec, tailStr, args...)
+			for db.Next() {
+				fmt.Println(rec.Str)
+			}
+		}
+		clauseStr, args := dbmap.In("num", []int{2, 4})
+		show("WHERE "+clauseStr+" ORDER BY num", args)
+		clauseStr, args = dbmap.In("str", []string{"Aramis"})
+		show("WHERE "+clauseStr, args)
+		clauseStr, args = dbmap.In("num", []int64{})
+		show("WHERE "+clauseStr, args)
+		clauseStr, args = dbmap.In("str", []string{"Athos", "Porthos"})
+		tailStr, args, _ := glRecDsc.Where(clauseStr, args...).And("num > ?", 1).Tail()
+		show(tailStr, args)
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// WHERE num IN (?, ?) ORDER BY num [2 4]
+	// Porthos
+	// d'Artagnan
+	// WHERE str IN (?) [Aramis]
+	// Aramis
+	// WHERE 1 = 0 []
+	// WHERE (str IN (?, ?)) AND (num > ?) [Athos Porthos 1]
+	// Porthos
+	// <nil>
+}
+
+// This example demonstrates the creation of a table and its indexes in a way
+// that can be repeated each time a program starts.
+func ExampleWrapType_CreateIfNotExists() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		createStr, idxList := glRecDsc.CreateStrIfNotExists()
+		sort.Strings(idxList)
+		fmt.Println(createStr)
+		fmt.Println(strings.Join(idxList, "\n"))
+		db := glRecDsc.Wrap(hnd)
+		db.CreateIfNotExists()
+		db.Insert(&recType{Str: "abc", Num: 1})
+		db.CreateIfNotExists()
+		fmt.Println(db.Count(""), db.Err())
+		db.Create()
+		fmt.Println(db.OK())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE IF NOT EXISTS rec (str text, num integer);
+	// CREATE INDEX IF NOT EXISTS rec_num ON rec (num, str)
+	// CREATE INDEX IF NOT EXISTS rec_str ON rec (str, num)
+	// 1 <nil>
+	// false
+}
+
+// This example demonstrates that boolean fields, including those of a named
+// boolean type, are stored as the integers 1 and 0 and are retrieved as the
+// values that were stored.
+func ExampleDscType_SelectArg() {
+	type activeType bool
+	type flagRecType struct {
+		ID     int64      `db_primary:"*" db_table:"flag"`
+		Name   string     `db:"name"`
+		Flag   bool       `db:"flag"`
+		Active activeType `db:"active"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(flagRecType{}).Wrap(hnd)
+		db.Create()
+		db.Insert(&flagRecType{Name: "yes", Flag: true, Active: true})
+		db.Insert(&flagRecType{Name: "no", Flag: false, Active: false})
+		db.Insert(&flagRecType{Name: "mixed", Flag: true, Active: false})
+		var str string
+		err = hnd.QueryRow("SELECT group_concat(flag || active, ' ') FROM flag").Scan(&str)
+		db.SetError(err)
+		fmt.Println(str)
+		var rec flagRecType
+		db.Query(&rec, "ORDER BY rowid")
+		for db.Next() {
+			fmt.Println(rec.Name, rec.Flag, rec.Active)
+		}
+		db.QueryRow(&rec, "WHERE flag = ? AND active = ?", false, false)
+		fmt.Println(rec.Name, rec.Flag, rec.Active)
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 11 00 10
+	// yes true true
+	// no false false
+	// mixed true false
+	// no false false
+}
+
+// colorType is an enumeration that is stored in the database by name.
+type colorType int
+
+const (
+	colorRed colorType = iota
+	colorGreen
+	colorBlue
+)
+
+var colorNameList = []string{"red", "green", "blue"}
+
+// Value implements driver.Valuer.
+func (c colorType) Value() (driver.Value, error) {
+	if c < 0 || int(c) >= len(colorNameList) {
+		return nil, fmt.Errorf("invalid color %d", int(c))
+	}
+	return colorNameList[c], nil
+}
+
+// Scan implements sql.Scanner.
+func (c *colorType) Scan(src interface{}) error {
+	var str string
+	switch v := src.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	}
+	for j, nameStr := range colorNameList {
+		if nameStr == str {
+			*c = colorType(j)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown color %v", src)
+}
+
+// String implements fmt.Stringer.
+func (c colorType) String() string {
+	return colorNameList[c]
+}
+
+// tokenType is a fixed-length identifier that is stored as a blob.
+type tokenType [4]byte
+
+// Value implements driver.Valuer.
+func (t tokenType) Value() (driver.Value, error) {
+	return t[:], nil
+}
+
+// Scan implements sql.Scanner.
+func (t *tokenType) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok || len(data) != len(t) {
+		return fmt.Errorf("cannot store %v in token", src)
+	}
+	copy(t[:], data)
+	return nil
+}
+
+// This example demonstrates fields of types that store and retrieve
+// themselves by implementing driver.Valuer and sql.Scanner. The column type
+// follows from the value that each type stores.
+func ExampleDescribe_valuer() {
+	type paintType struct {
+		ID    int64     `db_primary:"*" db_table:"paint"`
+		Name  string    `db:"name"`
+		Color colorType `db:"color"`
+		Token tokenType `db:"token"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(paintType{})
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&paintType{Name: "sky", Color: colorBlue, Token: tokenType{1, 2, 3, 4}})
+		db.Insert(&paintType{Name: "grass", Color: colorGreen, Token: tokenType{5, 6, 7, 8}})
+		var str string
+		err = hnd.QueryRow("SELECT group_concat(color, ' ') FROM paint").Scan(&str)
+		db.SetError(err)
+		fmt.Println(str)
+		var rec paintType
+		db.Query(&rec, "WHERE color = ? ORDER BY name", colorBlue)
+		for db.Next() {
+			fmt.Println(rec.Name, rec.Color, rec.Token)
+		}
+		db.Insert(&paintType{Name: "void", Color: colorType(7)})
+		fmt.Println(db.OK())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE paint (name text, color text, token blob);
+	// blue green
+	// sky blue [1 2 3 4]
+	// false
+}
+
+// This example demonstrates the reuse of a descriptor by separate calls, for
+// example from different packages, that describe the same record type.
+func ExampleDescribeCached() {
+	type noteType struct {
+		ID   int64  `db_primary:"*" db_table:"note"`
+		Text string `db:"text"`
+	}
+	var wg sync.WaitGroup
+	dscList := make([]dbmap.DscType, 4)
+	for j := range dscList {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			dscList[j], _ = dbmap.DescribeCached(&noteType{})
+		}(j)
+	}
+	wg.Wait()
+	for _, dsc := range dscList {
+		fmt.Println(dsc.SelectStr(""))
+	}
+	_, err := dbmap.DescribeCached(struct{ Num int }{})
+	fmt.Println(err)
+	// Output:
+	// SELECT rowid, text FROM note;
+	// SELECT rowid, text FROM note;
+	// SELECT rowid, text FROM note;
+	// SELECT rowid, text FROM note;
+	// at least one exported structure field must have "db" tag
+}
+
+// This example demonstrates the ordering of records by a column chosen, for
+// example, by the user of an application.
+func ExampleDscType_OrderBy() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for _, rec := range []recType{{Str: "Porthos", Num: 5}, {Str: "Athos", Num: 9}, {Str: "Aramis", Num: 7}} {
+			db.Insert(&rec)
+		}
+		for _, colStr := range []string{"str", "num", "rowid", "num; DROP TABLE rec"} {
+			var orderStr string
+			orderStr, err = glRecDsc.OrderBy(colStr, colStr == "rowid")
+			if err == nil {
+				var list []recType
+				db.QueryAll(&list, "WHERE num > ? "+orderStr, 0)
+				fmt.Println(orderStr, list)
+			} else {
+				fmt.Println(err)
+			}
+		}
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	// Output:
+	// ORDER BY str [{3 Aramis 7} {2 Athos 9} {1 Porthos 5}]
+	// ORDER BY num [{1 Porthos 5} {3 Aramis 7} {2 Athos 9}]
+	// ORDER BY rowid DESC [{3 Aramis 7} {2 Athos 9} {1 Porthos 5}]
+	// "num; DROP TABLE rec" is not a column of table rec
+	// <nil>
+}
+
+// This example demonstrates the refreshing of a record after its row has been
+// modified by another means, here a direct command.
+func ExampleWrapType_Reload() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		rec := recType{Str: "Athos", Num: 5}
+		db.Insert(&rec)
+		_, err = hnd.Exec("UPDATE rec SET num = num * 10 WHERE rowid = ?", rec.ID)
+		if err == nil {
+			db.Reload(&rec)
+			fmt.Println(rec, db.Err())
+			db.DeleteRec(&rec)
+			db.Reload(&rec)
+			fmt.Println(db.Err())
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// {1 Athos 50} <nil>
+	// dbmap query rec: sql: no rows in result set
+}
+
+// This example demonstrates a search for a term that contains a LIKE
+// wildcard. Because the wildcard is escaped, "5%" matches only a literal
+// percent sign and not, for example, "50 pieces".
+func ExampleDscType_Like() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j, str := range []string{"5% off", "50 pieces", "save 5%", "a_b"} {
+			db.Insert(&recType{Str: str, Num: int64(j + 1)})
+		}
+		fmt.Println(dbmap.EscapeLike(`5%_\`))
+		for _, termStr := range []string{"5%", "_"} {
+			var list []recType
+			clauseStr, args := glRecDsc.Like("str", termStr)
+			db.QueryAll(&list, "WHERE "+clauseStr+" ORDER BY num", args...)
+			fmt.Println(clauseStr, args, list)
+		}
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 5\%\_\\
+	// str LIKE ? ESCAPE '\' [%5\%%] [{1 5% off 1} {3 save 5% 3}]
+	// str LIKE ? ESCAPE '\' [%\_%] [{4 a_b 4}]
+	// <nil>
+}
+
+// This example demonstrates that a deletion is part of the active
+// transaction, so that it is undone when the transaction is rolled back.
+func ExampleWrapType_Delete() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"Athos", "Porthos", "Aramis"} {
+			db.Insert(&recType{Str: str, Num: int64(len(str))})
+		}
+		db.TransactionBegin()
+		db.Delete("WHERE str = ?", "Porthos")
+		fmt.Println(db.Count(""), db.TransactionRowsAffected())
+		db.TransactionRollback()
+		fmt.Println(db.Count(""), db.Exists("WHERE str = ?", "Porthos"))
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 2 1
+	// 3 true
+	// <nil>
+}
+
+// This example demonstrates a column constraint declared with a "db_check"
+// tag. The insertion of a record that violates it fails.
+func ExampleDscType_32() {
+	type personType struct {
+		ID   int64  `db_primary:"*" db_table:"person"`
+		Name string `db:"name"`
+		Age  int    `db:"age" db_check:"age >= 0"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(personType{})
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&personType{Name: "Athos", Age: 30})
+		db.Insert(&personType{Name: "Porthos", Age: -1})
+		fmt.Println(db.Err())
+		db.ClearError()
+		fmt.Println(db.Count(""))
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE person (name text, age integer CHECK (age >= 0));
+	// dbmap insert into person: CHECK constraint failed: age >= 0
+	// 1
+}
+
+// This example demonstrates the addition of columns to an existing table
+// whose record structure has gained fields. Existing rows receive the default
+// value of a new column. A column whose type conflicts with its field is
+// reported as an error.
+func ExampleWrapType_Migrate() {
+	type personType struct {
+		ID   int64  `db_primary:"*" db_table:"person"`
+		Name string `db:"name"`
+		Age  int    `db:"age" db_default:"0"`
+		Note string `db:"note" db_default:"none"`
+	}
+	type wrongType struct {
+		ID   int64 `db_primary:"*" db_table:"person"`
+		Name int64 `db:"name"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		_, err = hnd.Exec("CREATE TABLE person (name text); INSERT INTO person (name) VALUES ('Athos');")
+		if err == nil {
+			db := dbmap.MustDescribe(personType{}).Wrap(hnd)
+			db.Migrate()
+			missing, extra, _ := db.ColumnDrift()
+			fmt.Println(missing, extra)
+			db.Insert(&personType{Name: "Porthos", Age: 30, Note: "musketeer"})
+			var list []personType
+			db.QueryAll(&list, "ORDER BY rowid")
+			fmt.Println(list, db.Err())
+			wrongDb := dbmap.MustDescribe(wrongType{}).Wrap(hnd)
+			wrongDb.Migrate()
+			fmt.Println(wrongDb.Err())
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// [] []
+	// [{1 Athos 0 none} {2 Porthos 30 musketeer}] <nil>
+	// dbmap alter person: column "name" of table person is declared as integer but has type text in the database
+}
+
+// This example demonstrates the retrieval of the number of rows affected by
+// updates and deletions.
+func ExampleWrapType_RowsAffected() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		fmt.Println(db.RowsAffected(), db.Err())
+		db.ClearError()
+		db.Create()
+		for _, str := range []string{"Athos", "Porthos", "Aramis"} {
+			db.Insert(&recType{Str: str, Num: int64(len(str))})
+		}
+		fmt.Println(db.RowsAffected())
+		db.Update(recType{ID: 2, Str: "Porthos", Num: 8}, "num")
+		fmt.Println(db.RowsAffected())
+		db.Delete("WHERE num > ?", 5)
+		fmt.Println(db.RowsAffected())
+		db.Delete("WHERE num > ?", 5)
+		fmt.Println(db.RowsAffected(), db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 0 no command has been executed that affects rows
+	// 1
+	// 1
+	// 2
+	// 0 <nil>
+}
+
+// This example demonstrates a column that sorts without regard to case
+// because of its "db_collate" tag. Without the tag, the names that begin with
+// capital letters would precede the others.
+func ExampleDscType_33() {
+	type personType struct {
+		ID   int64  `db_primary:"*" db_table:"person"`
+		Name string `db:"name" db_collate:"NOCASE" db_index:"name1"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(personType{})
+		createStr, idxList := dsc.CreateStr()
+		fmt.Println(createStr)
+		fmt.Println(strings.Join(idxList, "\n"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"porthos", "Athos", "d'Artagnan", "Aramis", "athos"} {
+			db.Insert(&personType{Name: str})
+		}
+		var list []personType
+		db.QueryAll(&list, "ORDER BY name, rowid")
+		for _, rec := range list {
+			fmt.Println(rec.Name)
+		}
+		fmt.Println(db.Count("WHERE name = ?", "ATHOS"))
+		hnd.Close()
+		err = db.Err()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE person (name text COLLATE NOCASE);
+	// CREATE INDEX person_name ON person (name)
+	// Aramis
+	// Athos
+	// athos
+	// d'Artagnan
+	// porthos
+	// 2
+}
+
+// This example demonstrates the release of the database connection held by a
+// result set whose rows are not all retrieved. The connection is released by
+// CloseRows(), by the next query and by an error.
+func ExampleWrapType_CloseRows() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"Athos", "Porthos", "Aramis"} {
+			db.Insert(&recType{Str: str, Num: int64(len(str))})
+		}
+		var rec recType
+		db.Query(&rec, "ORDER BY rowid")
+		db.Next()
+		fmt.Println(rec.Str, hnd.Stats().InUse)
+		db.CloseRows()
+		fmt.Println(db.Next(), hnd.Stats().InUse)
+		db.Query(&rec, "ORDER BY rowid")
+		db.Next()
+		db.Query(&rec, "ORDER BY rowid DESC")
+		db.Next()
+		fmt.Println(rec.Str, hnd.Stats().InUse)
+		db.SetErrorf("stop")
+		fmt.Println(db.Next(), hnd.Stats().InUse)
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// Athos 1
+	// false 0
+	// Aramis 1
+	// false 0
+	// stop
+}
+
+// This example demonstrates the lookup of a record that is created if it
+// does not already exist.
+func ExampleWrapType_Get() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&recType{Str: "Athos", Num: 5})
+		for _, str := range []string{"Athos", "Porthos", "Porthos"} {
+			var rec recType
+			if db.Get(&rec, "WHERE str = ?", str) {
+				fmt.Println("found", rec, db.OK())
+			} else {
+				fmt.Println("not found", db.OK())
+				rec = recType{Str: str, Num: int64(len(str))}
+				db.Insert(&rec)
+			}
+		}
+		var rec recType
+		fmt.Println(db.Get(&rec, "WHERE nonexistent = 1"), db.Err() != nil)
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// found {1 Athos 5} true
+	// not found true
+	// found {2 Porthos 7} true
+	// false true
+}
+
+// This example demonstrates the round trip of float32 values, which are
+// stored as 64-bit reals. The retrieved values equal the stored ones exactly,
+// both with and without a "db_zero" tag.
+func ExampleDscType_34() {
+	type sampleType struct {
+		ID    int64   `db_primary:"*" db_table:"sample"`
+		Val   float32 `db:"val"`
+		Scale float32 `db:"scale" db_zero:"-1"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := dbmap.MustDescribe(sampleType{}).Wrap(hnd)
+		db.Create()
+		list := []sampleType{{Val: 0.1, Scale: 0.3}, {Val: 1.0 / 3, Scale: 0},
+			{Val: math.MaxFloat32, Scale: math.SmallestNonzeroFloat32}}
+		for j := range list {
+			db.Insert(&list[j])
+		}
+		var get []sampleType
+		db.QueryAll(&get, "ORDER BY rowid")
+		for j, rec := range get {
+			fmt.Println(rec.Val == list[j].Val, rec.Scale == list[j].Scale)
+		}
+		var val float64
+		err = hnd.QueryRow("SELECT val FROM sample WHERE rowid = 1").Scan(&val)
+		fmt.Println(val == float64(float32(0.1)), db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// true true
+	// true true
+	// true true
+	// true <nil>
+}
+
+// This example demonstrates the insertion of records that are skipped if
+// they duplicate the unique key of an existing record. The identifier of a
+// skipped record is left unchanged.
+func ExampleWrapType_InsertIgnore() {
+	type memberType struct {
+		ID    int64  `db_primary:"*" db_table:"member"`
+		Email string `db:"email" db_unique:"*"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(memberType{})
+		fmt.Println(dsc.InsertOrIgnoreStr())
+		db := dsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"a@b.com", "c@d.com", "a@b.com"} {
+			rec := memberType{Email: str}
+			db.InsertIgnore(&rec)
+			fmt.Println(rec.ID, db.RowsAffected())
+		}
+		db.Insert(&memberType{Email: "c@d.com"})
+		fmt.Println(db.Err())
+		db.ClearError()
+		fmt.Println(db.Count(""))
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// INSERT INTO member (email) VALUES (?) ON CONFLICT DO NOTHING;
+	// 1 1
+	// 2 1
+	// 0 0
+	// dbmap insert into member: UNIQUE constraint failed: member.email
+	// 2
+}
+
+// This example demonstrates the description of a record structure by its
+// type. A type that is not a structure is rejected.
+func ExampleDescribeType() {
+	dsc, err := dbmap.DescribeType(reflect.TypeOf(recType{}))
+	if err == nil {
+		fmt.Println(dsc.InsertStr())
+		dsc, err = dbmap.DescribeType(reflect.TypeOf(&recType{}))
+	}
+	if err == nil {
+		fmt.Println(dsc.SelectStr(""))
+		_, err = dbmap.DescribeType(reflect.TypeOf([]recType{}))
+	}
+	fmt.Println(err)
+	// Output:
+	// INSERT INTO rec (str, num) VALUES (?, ?);
+	// SELECT rowid, str, num FROM rec;
+	// specified address must be of structure with one or more fields that have a "db" tag
+}
+
+// This example demonstrates the retrieval of aggregates for groups of
+// records. The result is stored in a structure that has a field for each
+// column of the result; the last column has no alias, so its field is tagged
+// with the expression itself.
+func ExampleWrapType_QueryGrouped() {
+	type groupType struct {
+		Str   string `db:"str"`
+		Count int64  `db:"cnt"`
+		Sum   int64  `db:"SUM(num)"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for j, str := range []string{"abc", "def", "abc", "ghi", "abc", "def"} {
+			db.Insert(&recType{Str: str, Num: int64(j)})
+		}
+		selStr := "str, COUNT(*) AS cnt, SUM(num)"
+		fmt.Println(glRecDsc.GroupedStr(selStr, "str", "COUNT(*) > ?", "WHERE num < ?"))
+		var list []groupType
+		db.QueryGrouped(&list, selStr, "str", "COUNT(*) > ?", "WHERE num < ?", 5, 1)
+		fmt.Println(list)
+		list = nil
+		db.QueryGrouped(&list, selStr, "str", "", "")
+		fmt.Println(list)
+		db.QueryGrouped(&list, selStr, "str", "COUNT(*) > ?", "")
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// SELECT str, COUNT(*) AS cnt, SUM(num) FROM rec WHERE num < ? GROUP BY str HAVING COUNT(*) > ? ORDER BY str;
+	// [{abc 3 6}]
+	// [{abc 3 6} {def 2 6} {ghi 1 3}]
+	// dbmap query rec: grouped query has 1 placeholders but 0 parameters
+}
+
+// This example demonstrates commands whose parameters are marked by name
+// rather than by question marks. The other details of the SQLite dialect are
+// retained.
+func ExampleDscType_WithPlaceholder() {
+	dsc := glRecDsc.WithPlaceholder(func(i int) string {
+		return fmt.Sprintf(":p%d", i)
+	})
+	fmt.Println(dsc.InsertStr())
+	fmt.Println(dsc.UpdateStr("num"))
+	fmt.Println(dsc.UpsertStr([]string{"str"}, []string{"num"}))
+	tailStr, args, err := dsc.Where("str = ?", "abc").And("num < ?", 10).Tail()
+	fmt.Println(dsc.SelectStr(tailStr), args, err)
+	fmt.Println(dsc.WithPlaceholder(nil).UpdateStr("num"))
+	// Output:
+	// INSERT INTO rec (str, num) VALUES (:p1, :p2);
+	// UPDATE rec SET num = :p1 WHERE rowid = :p2;
+	// INSERT INTO rec (str, num) VALUES (:p1, :p2) ON CONFLICT (str) DO UPDATE SET num = excluded.num RETURNING rowid;
+	// SELECT rowid, str, num FROM rec WHERE (str = :p1) AND (num < :p2); [abc 10] <nil>
+	// UPDATE rec SET num = ? WHERE rowid = ?;
+}
+
+// This example demonstrates the validation of the sequence that follows the
+// index name in a key segment.
+func ExampleDscType_35() {
+	type goodType struct {
+		Name string `db:"name" db_table:"good" db_index:"name1"`
+	}
+	type letterType struct {
+		Name string `db:"name" db_table:"letter" db_index:"nameX"`
+	}
+	type mixedType struct {
+		Name string `db:"name" db_table:"mixed" db_index:"name2b"`
+	}
+	dsc, err := dbmap.Describe(goodType{})
+	if err == nil {
+		_, idxList := dsc.CreateStr()
+		fmt.Println(idxList)
+	}
+	_, err = dbmap.Describe(letterType{})
+	fmt.Println(err)
+	_, err = dbmap.Describe(mixedType{})
+	fmt.Println(err)
+	// Output:
+	// [CREATE INDEX good_name ON good (name)]
+	// index sequence must be an integer: nameX
+	// index sequence must be an integer: name2b
+}
+
+// This example demonstrates the error reported when two fields claim the
+// same position in an index, which would leave the order of the index
+// columns undefined.
+func ExampleDscType_36() {
+	type placeType struct {
+		City    string `db:"city" db_table:"place" db_index:"loc1"`
+		Country string `db:"country" db_index:"loc1"`
+	}
+	type memberType struct {
+		Tenant int64  `db:"tenant" db_table:"member" db_unique:"handle1"`
+		Handle string `db:"handle" db_unique:"handle1"`
+	}
+	_, err := dbmap.Describe(placeType{})
+	fmt.Println(err)
+	_, err = dbmap.Describe(memberType{})
+	fmt.Println(err)
+	// Output:
+	// duplicate index sequence 1 for index loc
+	// duplicate index sequence 1 for index handle
+}
+
+// This example demonstrates the processing of records one at a time by a
+// callback. The second pass stops at the first record that the callback
+// rejects. In both cases, the connection held by the result set is released.
+func ExampleWrapType_ForEach() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"Athos", "Porthos", "Aramis"} {
+			db.Insert(&recType{Str: str, Num: int64(len(str))})
+		}
+		var rec recType
+		var sum int64
+		db.ForEach(&rec, "ORDER BY rowid", func() error {
+			sum += rec.Num
+			return nil
+		})
+		fmt.Println(sum, hnd.Stats().InUse, db.Err())
+		db.ForEach(&rec, "WHERE num > ? ORDER BY rowid", func() error {
+			fmt.Println(rec.Str)
+			if rec.Num > 6 {
+				return fmt.Errorf("%s is too long", rec.Str)
+			}
+			return nil
+		}, 0)
+		fmt.Println(hnd.Stats().InUse, db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 18 0 <nil>
+	// Athos
+	// Porthos
+	// 0 Porthos is too long
+}
+
+// This example demonstrates the opening and wrapping of a database in one
+// step. The database handle is closed by way of DB().
+func ExampleDscType_Connect() {
+	os.Remove(dbFileStr)
+	db, err := glRecDsc.Connect("sqlite3", dbFileStr)
+	if err == nil {
+		db.Create()
+		db.Insert(&recType{Str: "Athos", Num: 5})
+		fmt.Println(db.Count(""), db.Err())
+		err = db.DB().Close()
+	}
+	fmt.Println(err)
+	_, err = dbmap.Open("nodriver", dbFileStr)
+	fmt.Println(err)
+	// Output:
+	// 1 <nil>
+	// <nil>
+	// sql: unknown driver "nodriver" (forgotten import?)
+}
+
+// This example demonstrates the update of two columns in all records that
+// satisfy a condition.
+func ExampleWrapType_UpdateWhere() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		for _, str := range []string{"Athos", "Porthos", "Aramis", "d'Artagnan"} {
+			db.Insert(&recType{Str: str, Num: int64(len(str))})
+		}
+		setCols := map[string]interface{}{"str": "done", "num": 0}
+		cmdStr, args, _ := glRecDsc.UpdateWhereArg(setCols, "WHERE num < ?", 7)
+		fmt.Println(cmdStr, args)
+		db.UpdateWhere(setCols, "WHERE num < ?", 7)
+		fmt.Println(db.RowsAffected())
+		var list []recType
+		db.QueryAll(&list, "ORDER BY rowid")
+		fmt.Println(list)
+		db.UpdateWhere(map[string]interface{}{"size": 1}, "")
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// UPDATE rec SET num = ?, str = ? WHERE num < ?; [0 done 7]
+	// 2
+	// [{1 done 0} {2 Porthos 7} {3 done 0} {4 d'Artagnan 10}]
+	// dbmap update rec: field name "size" not in structure
+}
+
+// This example demonstrates the round trip of binary data, including zero
+// and 0xFF bytes, through blob columns. The second field has a named byte
+// slice type.
+func ExampleDscType_37() {
+	type hashType []byte
+	type fileType struct {
+		ID   int64    `db_primary:"*" db_table:"file"`
+		Data []byte   `db:"data"`
+		Hash hashType `db:"hash"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(fileType{})
+		createStr, _ := dsc.CreateStr()
+		fmt.Println(createStr)
+		db := dsc.Wrap(hnd)
+		db.Create()
+		put := fileType{Data: []byte{0x00, 0xFF, 'a', 0x00, 0x80}, Hash: hashType{0xFF, 0x00}}
+		db.Insert(&put)
+		var get fileType
+		db.QueryRow(&get, "WHERE rowid = ?", put.ID)
+		fmt.Printf("% x | % x\n", get.Data, get.Hash)
+		var typeStr string
+		err = hnd.QueryRow("SELECT typeof(data) || ' ' || typeof(hash) FROM file").Scan(&typeStr)
+		fmt.Println(typeStr, db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE TABLE file (data blob, hash blob);
+	// 00 ff 61 00 80 | ff 00
+	// blob blob <nil>
+}
+
+// This example demonstrates the insertion of a subset of columns. The
+// database assigns the omitted column its default value, and the identifier
+// of the new record is stored in its ID field.
+func ExampleWrapType_InsertCols() {
+	type taskType struct {
+		ID       int64  `db_primary:"*" db_table:"task"`
+		Title    string `db:"title"`
+		Priority int64  `db:"priority" db_default:"3"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(taskType{})
+		fmt.Println(dsc.InsertColsStr("title"))
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&taskType{Title: "laundry", Priority: 1})
+		rec := taskType{Title: "dishes", Priority: 5}
+		db.InsertCols(&rec, "title")
+		fmt.Println(rec.ID, db.Err())
+		var list []taskType
+		db.QueryAll(&list, "ORDER BY rowid")
+		fmt.Println(list, db.Err())
+		db.InsertCols(&rec, "title", "due")
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// INSERT INTO task (title) VALUES (?);
+	// 2 <nil>
+	// [{1 laundry 1} {2 dishes 3}] <nil>
+	// dbmap insert into task: field name "due" not in structure
+}
+
+// This example demonstrates the use of one record structure for two tables
+// with the same columns. The original descriptor is unaffected by the copy.
+func ExampleDscType_WithTable() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := glRecDsc.WithTable("rec_2024")
+		fmt.Println(dsc.TableName(), dsc.Indexes())
+		fmt.Println(glRecDsc.TableName(), glRecDsc.Indexes())
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&recType{Str: "Athos", Num: 1})
+		dbNew := dsc.Wrap(hnd)
+		dbNew.Create()
+		dbNew.Insert(&recType{Str: "Porthos", Num: 2})
+		dbNew.Insert(&recType{Str: "Aramis", Num: 3})
+		var rec recType
+		dbNew.QueryRow(&rec, "WHERE num = ?", 3)
+		fmt.Println(rec, db.Count(""), dbNew.Count(""))
+		fmt.Println(db.Err(), dbNew.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// rec_2024 [rec_2024_num rec_2024_str]
+	// rec [rec_num rec_str]
+	// {2 Aramis 3} 1 2
+	// <nil> <nil>
+}
+
+// This example demonstrates a unique index on two columns, declared with
+// marked segments in "db_index" tags. A pair of values may occur only once,
+// although each value may repeat.
+func ExampleDscType_38() {
+	type seatType struct {
+		ID   int64  `db_primary:"*" db_table:"seat"`
+		Hall string `db:"hall" db_index:"!place1, hall1"`
+		Num  int64  `db:"num" db_index:"!place2"`
+	}
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		dsc := dbmap.MustDescribe(seatType{})
+		for _, str := range dsc.IndexStatements() {
+			fmt.Println(str)
+		}
+		db := dsc.Wrap(hnd)
+		db.Create()
+		db.Insert(&seatType{Hall: "east", Num: 1})
+		db.Insert(&seatType{Hall: "east", Num: 2})
+		db.Insert(&seatType{Hall: "west", Num: 1})
+		fmt.Println(db.Err())
+		db.Insert(&seatType{Hall: "east", Num: 2})
+		fmt.Println(db.Err())
+		db.ClearError()
+		fmt.Println(db.Count(""))
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// CREATE INDEX seat_hall ON seat (hall)
+	// CREATE UNIQUE INDEX seat_place ON seat (hall, num)
+	// <nil>
+	// dbmap insert into seat: UNIQUE constraint failed: seat.hall, seat.num
+	// 3
+}
+
+// This example demonstrates the operation context of retained errors. The
+// underlying error remains available to errors.Is() and errors.As().
+func ExampleOpError() {
+	var hnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		db.Create()
+		var rec recType
+		db.QueryRow(&rec, "WHERE num = ?", 42)
+		fmt.Println(db.Err())
+		fmt.Println(errors.Is(db.Err(), sql.ErrNoRows))
+		var opErr *dbmap.OpError
+		if errors.As(db.Err(), &opErr) {
+			fmt.Println(opErr.Op, opErr.Table)
+		}
+		db.ClearError()
+		db.Create()
+		fmt.Println(db.Err())
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// dbmap query rec: sql: no rows in result set
+	// true
+	// query rec
+	// dbmap create rec: table rec already exists
+}
+
+// This example demonstrates the retry of commands that fail because another
+// connection holds a write lock on the database. The busy timeout of the
+// wrapper's connection is set to zero so that such a command fails
+// immediately. The retries give up while the lock is held and succeed once
+// the transaction that holds it ends.
+func ExampleWrapType_SetBusyRetry() {
+	var hnd, lockHnd *sql.DB
+	var err error
+	os.Remove(dbFileStr)
+	hnd, err = sql.Open("sqlite3", dbFileStr)
+	if err == nil {
+		db := glRecDsc.Wrap(hnd)
+		// Report a locked database at once rather than waiting in the
+		// driver. The pragma applies to a single connection.
+		hnd.SetMaxOpenConns(1)
+		_, err = hnd.Exec("PRAGMA busy_timeout = 0")
+		db.SetError(err)
+		db.Create()
+		db.Insert(&recType{Str: "Athos", Num: 5})
+		err = db.Err()
+		if err == nil {
+			lockHnd, err = sql.Open("sqlite3", dbFileStr)
+		}
+		if err == nil {
+			var tx *sql.Tx
+			tx, err = lockHnd.Begin()
+			if err == nil {
+				_, err = tx.Exec("INSERT INTO rec (str, num) VALUES (?, ?)", "Porthos", 7)
+			}
+			if err == nil {
+				db.Insert(&recType{Str: "Aramis", Num: 6})
+				fmt.Println(db.Err())
+				db.ClearError()
+				db.SetBusyRetry(2, time.Millisecond)
+				db.Insert(&recType{Str: "Aramis", Num: 6})
+				fmt.Println(db.Err())
+				db.ClearError()
+				done := make(chan error)
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					done <- tx.Commit()
+				}()
+				db.SetBusyRetry(8, 5*time.Millisecond)
+				db.Insert(&recType{Str: "Aramis", Num: 6})
+				fmt.Println(db.Err(), <-done)
+				fmt.Println(db.Count(""))
+			}
+			lockHnd.Close()
+		}
+		hnd.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// dbmap insert into rec: database is locked
+	// dbmap insert into rec: database is locked
+	// <nil> <nil>
+	// 3
+}
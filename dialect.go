@@ -0,0 +1,376 @@
+package dbmap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect specifies the database-specific details of the SQL commands that
+// are generated by DscType. SQLiteDialect is used unless another dialect is
+// specified with DscType.WithDialect(). Applications can implement this
+// interface in order to generate commands for databases that are not
+// supported by this package. Embedding SQLiteDialect or PostgresDialect in
+// such an implementation supplies the methods that do not need to differ.
+type Dialect interface {
+	// PlaceholderMark returns the parameter marker for the i'th argument of a
+	// command. The first argument has an index of 1.
+	PlaceholderMark(i int) string
+	// PrimaryKeyColumn returns the name of the column that holds the value
+	// of the field tagged with db_primary.
+	PrimaryKeyColumn() string
+	// AutoIncrementType returns the type and constraints of the primary key
+	// column when it needs to be declared in a CREATE TABLE command. An empty
+	// string indicates that the database maintains the column implicitly.
+	AutoIncrementType() string
+	// ColumnType returns the column type that corresponds with typeStr, one
+	// of "integer", "real", "text", "blob" and "datetime".
+	ColumnType(typeStr string) string
+	// QuoteIdent returns str enclosed in identifier quotes.
+	QuoteIdent(str string) string
+	// ReservedWord returns true if str, without regard to case, is reserved
+	// and needs to be quoted when used as an identifier.
+	ReservedWord(str string) bool
+	// ReturningID returns the clause that is appended to an insertion
+	// command in order to retrieve the identifier assigned to the new record.
+	// An empty string indicates that sql.Result.LastInsertId() is used
+	// instead.
+	ReturningID() string
+	// OptimizeStr returns the command that performs routine optimization
+	// of the database, for example by updating the statistics used by the
+	// query planner where they are out of date.
+	OptimizeStr() string
+	// ForeignKeysStr returns the command that enables the enforcement of
+	// foreign key constraints, or an empty string if the database always
+	// enforces them.
+	ForeignKeysStr() string
+	// SchemaVersionStr returns the commands that are executed, in order, to
+	// read the schema version of the database. The last command returns a
+	// single row with the version as an integer.
+	SchemaVersionStr() []string
+	// SetSchemaVersionStr returns the commands that are executed, in order,
+	// to set the schema version of the database to v.
+	SetSchemaVersionStr(v int) []string
+	// IndexListStr returns a query that retrieves the names of the indexes
+	// of the table whose name is passed as its single argument. Indexes that
+	// the database creates automatically, for example to enforce UNIQUE and
+	// PRIMARY KEY constraints, are excluded.
+	IndexListStr() string
+	// ColumnListStr returns a query that retrieves the name and declared type
+	// of each column of the table whose name is passed as its single
+	// argument, in table order.
+	ColumnListStr() string
+	// MaxIdentLength returns the greatest number of bytes permitted in an
+	// identifier, or zero if there is no practical limit.
+	MaxIdentLength() int
+	// UpsertClause returns the clause that follows the VALUES portion of an
+	// insertion command so that, if the values of the conflictCols columns
+	// match those of an existing record, the updateCols columns of that
+	// record are updated instead.
+	UpsertClause(conflictCols, updateCols []string) string
+	// IgnoreClause returns the clause that follows the VALUES portion of an
+	// insertion command so that a record whose insertion would violate a
+	// unique constraint is skipped without error.
+	IgnoreClause() string
+	// LimitClause returns the clause that restricts the records retrieved by
+	// a query to limitStr records after skipping offsetStr records. Each is a
+	// number or a placeholder mark, or empty if the query is not restricted in
+	// that way. At least one is not empty.
+	LimitClause(limitStr, offsetStr string) string
+	// BoolLiteral returns the literal that represents b in the column of a
+	// bool field.
+	BoolLiteral(b bool) string
+	// RandomFunc returns an expression that has a random value for each
+	// record, suitable for ordering records randomly.
+	RandomFunc() string
+	// CollateClause returns the clause, for example "COLLATE NOCASE", that
+	// applies the collation named in a "db_collate" tag or an index tag to a
+	// column or key segment. An empty string indicates that the database has
+	// no equivalent and the column's default collation is used.
+	CollateClause(nameStr string) string
+}
+
+// onConflictClause implements Dialect.UpsertClause() for databases that
+// support the ON CONFLICT clause of SQLite and PostgreSQL.
+func onConflictClause(conflictCols, updateCols []string) string {
+	var list strListType
+	for _, nameStr := range updateCols {
+		list.appendf("%s = excluded.%s", nameStr, nameStr)
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(conflictCols, ", "), list.join())
+}
+
+// limitOffsetClause implements Dialect.LimitClause() with LIMIT and OFFSET
+// clauses. noLimitStr is the limit that precedes an offset when the number of
+// records is not restricted; if empty, the OFFSET clause stands alone.
+func limitOffsetClause(limitStr, offsetStr, noLimitStr string) string {
+	var list []string
+	if len(limitStr) > 0 {
+		list = append(list, "LIMIT "+limitStr)
+	} else if len(noLimitStr) > 0 {
+		list = append(list, "LIMIT "+noLimitStr)
+	}
+	if len(offsetStr) > 0 {
+		list = append(list, "OFFSET "+offsetStr)
+	}
+	return strings.Join(list, " ")
+}
+
+// quoteIdent encloses str in double quotes, doubling any that it contains.
+func quoteIdent(str string) string {
+	return `"` + strings.Replace(str, `"`, `""`, -1) + `"`
+}
+
+// SQLiteDialect implements Dialect for SQLite. It uses question marks as
+// placeholders and the implicit rowid column as the primary key.
+type SQLiteDialect struct{}
+
+// PlaceholderMark implements Dialect.
+func (SQLiteDialect) PlaceholderMark(i int) string {
+	return "?"
+}
+
+// PrimaryKeyColumn implements Dialect.
+func (SQLiteDialect) PrimaryKeyColumn() string {
+	return "rowid"
+}
+
+// AutoIncrementType implements Dialect.
+func (SQLiteDialect) AutoIncrementType() string {
+	return ""
+}
+
+// ColumnType implements Dialect.
+func (SQLiteDialect) ColumnType(typeStr string) string {
+	return typeStr
+}
+
+// QuoteIdent implements Dialect.
+func (SQLiteDialect) QuoteIdent(str string) string {
+	return quoteIdent(str)
+}
+
+// ReservedWord implements Dialect.
+func (SQLiteDialect) ReservedWord(str string) bool {
+	return glSQLiteReservedMap[strings.ToUpper(str)]
+}
+
+// ReturningID implements Dialect.
+func (SQLiteDialect) ReturningID() string {
+	return ""
+}
+
+// OptimizeStr implements Dialect.
+func (SQLiteDialect) OptimizeStr() string {
+	return "PRAGMA optimize;"
+}
+
+// ForeignKeysStr implements Dialect. The pragma applies to the connection on
+// which it is executed.
+func (SQLiteDialect) ForeignKeysStr() string {
+	return "PRAGMA foreign_keys = ON;"
+}
+
+// SchemaVersionStr implements Dialect. The version is held in the
+// user_version field of the database file header.
+func (SQLiteDialect) SchemaVersionStr() []string {
+	return []string{"PRAGMA user_version;"}
+}
+
+// SetSchemaVersionStr implements Dialect.
+func (SQLiteDialect) SetSchemaVersionStr(v int) []string {
+	return []string{fmt.Sprintf("PRAGMA user_version = %d;", v)}
+}
+
+// IndexListStr implements Dialect. Only indexes created with CREATE INDEX
+// are retrieved.
+func (SQLiteDialect) IndexListStr() string {
+	return "SELECT name FROM pragma_index_list(?) WHERE origin = 'c';"
+}
+
+// ColumnListStr implements Dialect. The implicit rowid column is not
+// included.
+func (SQLiteDialect) ColumnListStr() string {
+	return "SELECT name, type FROM pragma_table_info(?) ORDER BY cid;"
+}
+
+// MaxIdentLength implements Dialect.
+func (SQLiteDialect) MaxIdentLength() int {
+	return 0
+}
+
+// UpsertClause implements Dialect. Upserts require SQLite 3.24 or later.
+func (SQLiteDialect) UpsertClause(conflictCols, updateCols []string) string {
+	return onConflictClause(conflictCols, updateCols)
+}
+
+// IgnoreClause implements Dialect. The clause requires SQLite 3.24 or later.
+func (SQLiteDialect) IgnoreClause() string {
+	return "ON CONFLICT DO NOTHING"
+}
+
+// LimitClause implements Dialect. SQLite requires a LIMIT clause before an
+// OFFSET clause; a limit of -1 does not restrict the number of records.
+func (SQLiteDialect) LimitClause(limitStr, offsetStr string) string {
+	return limitOffsetClause(limitStr, offsetStr, "-1")
+}
+
+// BoolLiteral implements Dialect.
+func (SQLiteDialect) BoolLiteral(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// RandomFunc implements Dialect.
+func (SQLiteDialect) RandomFunc() string {
+	return "RANDOM()"
+}
+
+// CollateClause implements Dialect. SQLite provides the collations BINARY,
+// NOCASE and RTRIM; others can be registered with the database driver.
+func (SQLiteDialect) CollateClause(nameStr string) string {
+	return "COLLATE " + nameStr
+}
+
+// PostgresDialect implements Dialect for PostgreSQL. It uses numbered
+// placeholders ($1, $2, ...) and a BIGSERIAL column named id as the primary
+// key. Assigned identifiers are retrieved with a RETURNING clause.
+type PostgresDialect struct{}
+
+// PlaceholderMark implements Dialect.
+func (PostgresDialect) PlaceholderMark(i int) string {
+	return "$" + strconv.Itoa(i)
+}
+
+// PrimaryKeyColumn implements Dialect.
+func (PostgresDialect) PrimaryKeyColumn() string {
+	return "id"
+}
+
+// AutoIncrementType implements Dialect.
+func (PostgresDialect) AutoIncrementType() string {
+	return "BIGSERIAL PRIMARY KEY"
+}
+
+var glPostgresTypeMap = tmType{
+	"blob":     "bytea",
+	"datetime": "timestamp with time zone",
+	"integer":  "bigint",
+	"real":     "double precision",
+	"text":     "text",
+}
+
+// ColumnType implements Dialect.
+func (PostgresDialect) ColumnType(typeStr string) string {
+	str, ok := glPostgresTypeMap[typeStr]
+	if ok {
+		return str
+	}
+	return typeStr
+}
+
+// QuoteIdent implements Dialect.
+func (PostgresDialect) QuoteIdent(str string) string {
+	return quoteIdent(str)
+}
+
+// ReservedWord implements Dialect.
+func (PostgresDialect) ReservedWord(str string) bool {
+	return glPostgresReservedMap[strings.ToUpper(str)]
+}
+
+// ReturningID implements Dialect.
+func (PostgresDialect) ReturningID() string {
+	return "RETURNING id"
+}
+
+// OptimizeStr implements Dialect.
+func (PostgresDialect) OptimizeStr() string {
+	return "VACUUM ANALYZE;"
+}
+
+// ForeignKeysStr implements Dialect.
+func (PostgresDialect) ForeignKeysStr() string {
+	return ""
+}
+
+// glPostgresSchemaStr creates the table that holds the schema version in the
+// absence of an equivalent to the SQLite user_version pragma.
+const glPostgresSchemaStr = "CREATE TABLE IF NOT EXISTS dbmap_schema (version integer NOT NULL);"
+
+// SchemaVersionStr implements Dialect. The version is held in the table
+// dbmap_schema, which is created if necessary.
+func (PostgresDialect) SchemaVersionStr() []string {
+	return []string{glPostgresSchemaStr,
+		"SELECT COALESCE(MAX(version), 0) FROM dbmap_schema;"}
+}
+
+// IndexListStr implements Dialect.
+func (PostgresDialect) IndexListStr() string {
+	return "SELECT indexname FROM pg_indexes WHERE tablename = $1 AND " +
+		"indexname NOT IN (SELECT conname FROM pg_constraint);"
+}
+
+// ColumnListStr implements Dialect.
+func (PostgresDialect) ColumnListStr() string {
+	return "SELECT column_name, data_type FROM information_schema.columns " +
+		"WHERE table_name = $1 ORDER BY ordinal_position;"
+}
+
+// MaxIdentLength implements Dialect. Longer identifiers are truncated by
+// PostgreSQL.
+func (PostgresDialect) MaxIdentLength() int {
+	return 63
+}
+
+// SetSchemaVersionStr implements Dialect.
+func (PostgresDialect) SetSchemaVersionStr(v int) []string {
+	return []string{glPostgresSchemaStr, "DELETE FROM dbmap_schema;",
+		fmt.Sprintf("INSERT INTO dbmap_schema (version) VALUES (%d);", v)}
+}
+
+// UpsertClause implements Dialect.
+func (PostgresDialect) UpsertClause(conflictCols, updateCols []string) string {
+	return onConflictClause(conflictCols, updateCols)
+}
+
+// IgnoreClause implements Dialect.
+func (PostgresDialect) IgnoreClause() string {
+	return "ON CONFLICT DO NOTHING"
+}
+
+// LimitClause implements Dialect.
+func (PostgresDialect) LimitClause(limitStr, offsetStr string) string {
+	return limitOffsetClause(limitStr, offsetStr, "")
+}
+
+// BoolLiteral implements Dialect. Bool fields are stored in bigint columns.
+func (PostgresDialect) BoolLiteral(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// RandomFunc implements Dialect.
+func (PostgresDialect) RandomFunc() string {
+	return "RANDOM()"
+}
+
+// CollateClause implements Dialect. The SQLite collation BINARY corresponds
+// with the PostgreSQL collation "C". NOCASE and RTRIM have no built-in
+// equivalent, so they are omitted; case-insensitive ordering can instead be
+// obtained with a nondeterministic collation created by the application. Other
+// names are quoted and passed on.
+func (PostgresDialect) CollateClause(nameStr string) string {
+	switch strings.ToUpper(nameStr) {
+	case "BINARY":
+		return `COLLATE "C"`
+	case "NOCASE", "RTRIM":
+		return ""
+	}
+	return "COLLATE " + quoteIdent(nameStr)
+}
@@ -78,7 +78,17 @@ occurs in the calling application during the database session, it may be
 desirable for the application to transfer the error to the WrapType instance by
 calling the SetError() method or the SetErrorf() method. At any time during the
 life cycle of the WrapType instance, the error state can be determined with a
-call to OK(). The error itself can be retrieved with a call to Err().
+call to OK(). The error itself can be retrieved with a call to Err(). An
+error that occurs in an operation such as Insert(), Update(), Delete(),
+Query() or Create() is retained as an *OpError, which names the operation and
+the table, for example "dbmap insert into rec: UNIQUE constraint failed:
+rec.str". Use errors.Is() and errors.As() rather than comparison to examine
+the underlying error, for example errors.Is(db.Err(), sql.ErrNoRows).
+
+A wrapper that is shared by goroutines, for example the request handlers of a
+server, is obtained with DscType.WrapSafe(). Each method of the returned
+SafeWrapType performs a self-contained operation and returns its error
+directly.
 
 Structure field tags
 
@@ -86,15 +96,55 @@ This mapping utility depends on tags to associate a Go application structure
 with a database table. The fields that will be managed by this package need to
 be exported, that is, have names that begin with an upper case letter. One and
 only one of these fields needs to have a tag named "db_table" whose value is
-the name of the database table or view.
+the name of the database table or view. If this name, or the name of a column,
+is an SQL reserved word (see SQLiteReservedWords()), it is quoted in the
+generated commands.
 
 If updates or insertions will be performed with a structure, it needs to have a
 "db_primary" tag. This tag identifies an int64 field that corresponds with the
-unique record identifier maintained by the database.
+unique record identifier maintained by the database. With the tag value "*",
+the identifier is held in the dialect's default column, rowid for SQLite and id
+for PostgreSQL. Any other value, for example `db_primary:"item_id"`, names the
+column, which is then declared by CreateStr() and used to select and update
+records. This accommodates views and existing tables.
+
+A table whose identity is made of one or more ordinary columns, for example
+TenantID and Code, can declare a composite key by giving each of those fields
+both a "db" tag and a "db_primary" tag. Updates then identify records by the
+values of these fields, and the generated CREATE TABLE command includes a
+PRIMARY KEY constraint for them.
+
+The fields of an embedded structure that has no tags of its own, for example
+a common base structure holding the primary key, are treated as fields of the
+outer structure. A column name may be used by only one field. Embedding a
+pointer to a structure with tagged fields is not supported.
 
 If a managed field does not have a "db_primary" tag, it must have a "db" tag
 that identifies the column name used in the database. If the tag value is an
-asterisk, the field name itself will be used.
+asterisk, the field name itself will be used. The column name may be followed
+by a comma and a column type, for example `db:"email,varchar(255)"`, that is
+declared by CreateStr() in place of the type that corresponds with the field.
+The values of the field are stored and retrieved as usual. The type must be
+compatible with the field: a numeric field cannot be declared as text or blob,
+and a timestamp field needs a type that includes DATE or TIME. In a strict
+table, the type must be one that SQLite permits. A field of a named type, for
+example `type statusType int`, is stored according to its underlying type. The
+values that such a field may hold can be restricted with DscType.WithEnum().
+Byte slices, including those of a named type such as `type hashType []byte`,
+are stored in blob columns and retrieved byte for byte, zero bytes included.
+Boolean fields, including those of a named boolean type, are stored as the
+integers 1 and 0. Fields of type float32 are stored in real columns, which hold
+64-bit values; since every float32 value has an exact 64-bit representation,
+the value retrieved is the one that was stored. Fields of type time.Time are
+stored in datetime columns; use *time.Time for a timestamp that may be NULL.
+The database/sql Null types, for example sql.NullString and sql.NullInt64, are
+stored like their non-null counterparts and can also hold NULL values. A field
+of any other type that implements driver.Valuer, with a pointer to it
+implementing sql.Scanner, is stored and retrieved by way of those methods. Its
+column type follows from the value that the type's zero value stores, for
+example text for a string, and is blob if that value is nil. Integers are
+stored as signed 64-bit values, so an error occurs when a uint or uint64 field
+holding a value greater than math.MaxInt64 is written.
 
 A field with an optional "db_index" tag will be indexed. The form of this tag
 is a comma-separated list of key segments. Each key segment is made of a name
@@ -102,14 +152,86 @@ portion and an integer sequence. For example `db_index:"name1, num2" indicates
 that the tagged field will be the first field in the index named 'name', and
 the second field in the index named 'num'. Even if a field is the only member
 of an index, it requires an integer suffix. The integer sequences for segments
-within a given key do not necessarily need to be sequential but they should not
-be duplicated.
+within a given key do not necessarily need to be sequential but they must not
+be duplicated; an error occurs if they are. Segments are ordered by the numeric
+value of their sequences, so "loc10" follows "loc9". A "db_unique" tag has the
+same form and declares indexes that reject duplicate keys. Alternatively, a key
+segment in a "db_index" tag that begins with an exclamation point, for example
+`db_index:"!email1"`, belongs to a unique index. The mark must appear on every
+segment of that index; an error occurs if an index is declared both unique and
+not unique. A key segment in either tag may be followed by a collation that
+applies to that segment, for example `db_unique:"email1 NOCASE"` declares a
+unique index that ignores case. The tag `db_unique:"*"` declares a unique index
+on the tagged column alone, named after the column. A "db_collate" tag, for
+example `db_collate:"NOCASE"`, declares the collation of the column itself.
+SQLite applies it to comparisons, ORDER BY clauses and indexes that involve
+the column. Collation names follow SQLite; other dialects translate them with
+Dialect.CollateClause() and omit those that have no equivalent.
+
+A field with a "db_notnull" tag, for example `db_notnull:"*"`, is declared
+with a NOT NULL constraint. Fields that can hold NULL, such as pointers and the
+database/sql Null types, cannot have this tag. A "db_default" tag specifies
+the default value of a column. The value is quoted for text columns and must be
+a number for integer and real columns. A value that begins with an equal sign,
+for example `db_default:"=CURRENT_TIMESTAMP"`, is an SQL expression that is
+used as it is. The value of a string field with a "db_trim" tag has leading
+and trailing white space removed when it is written to the database; the tag
+value "lower" also converts it to lower case. A timestamp field with a
+"db_autocreate" tag is set to the current time when the record is inserted, and
+one with a "db_autoupdate" tag is set when the record is inserted or updated.
+The current time is obtained from NowFunc. A number or string field with a
+"db_zero" tag, for example `db_zero:"-1"`, is stored with the tag value in
+place of its zero value, and the tag value is retrieved as the zero value. This
+accommodates tables that mark unset values with a sentinel rather than NULL.
+A field of any type, for example a map or a nested structure, with a "db_json"
+tag is stored in a text column as JSON, marshaled and unmarshaled with the
+encoding/json package.
+
+A "db_fk" tag, for example `db_fk:"author(code)"`, declares a foreign key
+constraint that requires the value of the tagged column to match the code
+column of a record in the author table. The suffix "cascade", as in
+`db_fk:"author(code) cascade"`, deletes referencing records along with the
+referenced one. SQLite does not permit a foreign key to refer to rowid, and it
+enforces the constraints only after WrapType.EnableForeignKeys() is called.
+
+A "db_check" tag, for example `db_check:"age >= 0"`, adds a CHECK constraint
+to the definition of the tagged column. The database then rejects an insertion
+or update that does not satisfy the expression, and the error is retained by
+the WrapType instance. The expression is copied verbatim into the CREATE TABLE
+command, so it must be written by the developer and never taken from user
+input.
+
+A field of type bool, time.Time or *time.Time with a "db_softdelete" tag makes
+deletions soft. WrapType.Delete() then sets the field (to 1 or to the current
+time) rather than removing records, and queries exclude the records in which
+it is set. WrapType.QueryWithDeleted() includes them and WrapType.DeleteHard()
+removes records.
+
+Dialects
+
+By default, commands are generated for SQLite. A descriptor that generates
+commands for another database is obtained by calling WithDialect() with an
+implementation of the Dialect interface. For example,
+dsc.WithDialect(dbmap.PostgresDialect{}) returns a descriptor that uses
+numbered placeholders, declares a BIGSERIAL primary key column named id and
+retrieves assigned identifiers with a RETURNING clause. Other databases can be
+targeted by implementing Dialect in the application. When only the parameter
+markers need to differ, for example for a driver that expects "$1" with
+otherwise SQLite-compatible commands, WithPlaceholder() is simpler.
+
+Runtime columns
+
+When the shape of a result is not known at compile time, DescribeColumns()
+builds a read-only descriptor from a list of column names and Go kinds. Rows
+retrieved with such a descriptor are stored in a map keyed by column name or in
+a slice of values.
 
 Limitations
 
-This wrapper to database/sql does not currently support table alterations. It
-does not directly support table joins but it can read database views (which in
-turn can include joins).
+This wrapper to database/sql supports table alterations only to the extent of
+adding columns and indexes; see WrapType.Migrate() and
+WrapType.MigrationPlan(). It does not directly support table joins but it can
+read database views (which in turn can include joins).
 
 */
 package dbmap
@@ -0,0 +1,157 @@
+package dbmap
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// intoFields returns the fields of the structure type tp that have a "db"
+// tag, keyed by column name. Unlike the structures passed to Describe(), tp
+// needs no "db_table" or "db_primary" tag.
+func intoFields(tp reflect.Type) (fldMap map[string]reflect.StructField, err error) {
+	var sfList sfListType
+	err = flatten(tp, nil, &sfList)
+	if err == nil {
+		fldMap = make(map[string]reflect.StructField)
+		for _, sf := range sfList {
+			nameStr := sf.Tag.Get("db")
+			if pos := strings.Index(nameStr, ","); pos >= 0 {
+				nameStr = strings.TrimSpace(nameStr[:pos])
+			}
+			if nameStr == "*" {
+				nameStr = sf.Name
+			}
+			if len(nameStr) > 0 {
+				fldMap[nameStr] = sf
+			}
+		}
+		if len(fldMap) == 0 {
+			err = fmt.Errorf(`structure %s must have at least one field with a "db" tag`, tp.String())
+		}
+	}
+	return
+}
+
+// intoArg returns the addresses of the fields of the structure recVl that
+// are associated with the columns in cols. An error occurs if a column has no
+// associated field.
+func intoArg(recVl reflect.Value, fldMap map[string]reflect.StructField, cols []string) (argList []interface{}, err error) {
+	argList = make([]interface{}, len(cols))
+	for j, nameStr := range cols {
+		sf, ok := fldMap[nameStr]
+		if !ok {
+			return nil, fmt.Errorf("column \"%s\" has no field in structure %s", nameStr,
+				recVl.Type().String())
+		}
+		argList[j] = recVl.FieldByIndex(sf.Index).Addr().Interface()
+	}
+	return
+}
+
+// QueryInto submits the complete SELECT command cmdStr with the parameters
+// args and stores the result in dstPtr. Unlike the other query methods, the
+// shape of the result is independent of the receiver's record type, so the
+// result of an aggregate query such as "SELECT author, COUNT(*) AS cnt FROM
+// title GROUP BY author" can be retrieved into a small structure. dstPtr is a
+// pointer to a structure, which receives the first row, or a pointer to a
+// slice of structures, to which all rows are appended. Each column of the
+// result, identified by its name or alias, is stored in the field whose "db"
+// tag names it; no "db_table" tag is needed. An error occurs if a column has
+// no such field, since this usually indicates a misspelled alias. Fields that
+// no column names are left unchanged. If dstPtr points to a structure and the
+// command returns no rows, sql.ErrNoRows is retained.
+func (w *WrapType) QueryInto(dstPtr interface{}, cmdStr string, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		var sliceVl, recVl reflect.Value
+		var recTp reflect.Type
+		ptrVl := reflect.ValueOf(dstPtr)
+		if ptrVl.Kind() == reflect.Ptr {
+			switch ptrVl.Elem().Kind() {
+			case reflect.Struct:
+				recVl = ptrVl.Elem()
+				recTp = recVl.Type()
+			case reflect.Slice:
+				if ptrVl.Elem().Type().Elem().Kind() == reflect.Struct {
+					sliceVl = ptrVl.Elem()
+					recTp = sliceVl.Type().Elem()
+					recVl = reflect.New(recTp).Elem()
+				}
+			}
+		}
+		var fldMap map[string]reflect.StructField
+		if recTp == nil {
+			w.sharePtr.errVal = fmt.Errorf("passed-in value of type %T must be a pointer to a "+
+				"structure or to a slice of structures", dstPtr)
+		} else {
+			fldMap, w.sharePtr.errVal = intoFields(recTp)
+		}
+		if w.sharePtr.errVal == nil {
+			rows := w.query(context.Background(), cmdStr, args...)
+			if w.sharePtr.errVal == nil {
+				var cols []string
+				var fldList []interface{}
+				cols, w.sharePtr.errVal = rows.Columns()
+				if w.sharePtr.errVal == nil {
+					fldList, w.sharePtr.errVal = intoArg(recVl, fldMap, cols)
+				}
+				if sliceVl.IsValid() {
+					listVl := sliceVl
+					for w.sharePtr.errVal == nil && rows.Next() {
+						recVl.Set(reflect.Zero(recTp))
+						w.sharePtr.errVal = scanRow(rows, fldList)
+						if w.sharePtr.errVal == nil {
+							listVl = reflect.Append(listVl, recVl)
+						}
+					}
+					if w.sharePtr.errVal == nil {
+						w.sharePtr.errVal = rows.Err()
+					}
+					if w.sharePtr.errVal == nil {
+						sliceVl.Set(listVl)
+					}
+				} else if w.sharePtr.errVal == nil {
+					if rows.Next() {
+						w.sharePtr.errVal = scanRow(rows, fldList)
+					} else {
+						w.sharePtr.errVal = rows.Err()
+						if w.sharePtr.errVal == nil {
+							w.sharePtr.errVal = sql.ErrNoRows
+						}
+					}
+				}
+				rows.Close()
+			}
+		}
+	}
+}
+
+// QueryGrouped submits the command returned by DscType.GroupedStr() for
+// selectExpr, groupByStr, havingStr and tailStr with the parameters args, and
+// stores the result in dstPtr as described in QueryInto(). Since the columns of
+// the result, for example "author, COUNT(*) AS cnt", generally differ from
+// those of the receiver's record structure, they are stored by name in the
+// fields of the structure type of dstPtr. A computed column needs an alias
+// unless the field's "db" tag repeats the expression as the database reports
+// it. args holds the parameters of tailStr followed by those of havingStr.
+// When the receiver's dialect uses question marks as placeholders, an error
+// occurs if their number does not match the number of parameters.
+func (w *WrapType) QueryGrouped(dstPtr interface{}, selectExpr, groupByStr, havingStr, tailStr string,
+	args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		if w.dsc.dialect.PlaceholderMark(1) == "?" {
+			count := strings.Count(selectExpr+groupByStr+havingStr+tailStr, "?")
+			if count != len(args) {
+				w.sharePtr.errVal = fmt.Errorf("grouped query has %d placeholders but %d parameters",
+					count, len(args))
+			}
+		}
+		if w.sharePtr.errVal == nil {
+			w.QueryInto(dstPtr, w.dsc.GroupedStr(selectExpr, groupByStr, havingStr, tailStr), args...)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package dbmap
+
+var glSQLiteReservedList = []string{
+	"ABORT", "ACTION", "ADD", "AFTER", "ALL", "ALTER", "ALWAYS", "ANALYZE",
+	"AND", "AS", "ASC", "ATTACH", "AUTOINCREMENT", "BEFORE", "BEGIN",
+	"BETWEEN", "BY", "CASCADE", "CASE", "CAST", "CHECK", "COLLATE", "COLUMN",
+	"COMMIT", "CONFLICT", "CONSTRAINT", "CREATE", "CROSS", "CURRENT",
+	"CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "DATABASE",
+	"DEFAULT", "DEFERRABLE", "DEFERRED", "DELETE", "DESC", "DETACH",
+	"DISTINCT", "DO", "DROP", "EACH", "ELSE", "END", "ESCAPE", "EXCEPT",
+	"EXCLUDE", "EXCLUSIVE", "EXISTS", "EXPLAIN", "FAIL", "FILTER", "FIRST",
+	"FOLLOWING", "FOR", "FOREIGN", "FROM", "FULL", "GENERATED", "GLOB",
+	"GROUP", "GROUPS", "HAVING", "IF", "IGNORE", "IMMEDIATE", "IN", "INDEX",
+	"INDEXED", "INITIALLY", "INNER", "INSERT", "INSTEAD", "INTERSECT",
+	"INTO", "IS", "ISNULL", "JOIN", "KEY", "LAST", "LEFT", "LIKE", "LIMIT",
+	"MATCH", "MATERIALIZED", "NATURAL", "NO", "NOT", "NOTHING", "NOTNULL",
+	"NULL", "NULLS", "OF", "OFFSET", "ON", "OR", "ORDER", "OTHERS", "OUTER",
+	"OVER", "PARTITION", "PLAN", "PRAGMA", "PRECEDING", "PRIMARY", "QUERY",
+	"RAISE", "RANGE", "RECURSIVE", "REFERENCES", "REGEXP", "REINDEX",
+	"RELEASE", "RENAME", "REPLACE", "RESTRICT", "RETURNING", "RIGHT",
+	"ROLLBACK", "ROW", "ROWS", "SAVEPOINT", "SELECT", "SET", "TABLE", "TEMP",
+	"TEMPORARY", "THEN", "TIES", "TO", "TRANSACTION", "TRIGGER", "UNBOUNDED",
+	"UNION", "UNIQUE", "UPDATE", "USING", "VACUUM", "VALUES", "VIEW",
+	"VIRTUAL", "WHEN", "WHERE", "WINDOW", "WITH", "WITHOUT",
+}
+
+var glPostgresReservedList = []string{
+	"ALL", "ANALYSE", "ANALYZE", "AND", "ANY", "ARRAY", "AS", "ASC",
+	"ASYMMETRIC", "AUTHORIZATION", "BINARY", "BOTH", "CASE", "CAST", "CHECK",
+	"COLLATE", "COLLATION", "COLUMN", "CONCURRENTLY", "CONSTRAINT", "CREATE",
+	"CROSS", "CURRENT_CATALOG", "CURRENT_DATE", "CURRENT_ROLE",
+	"CURRENT_SCHEMA", "CURRENT_TIME", "CURRENT_TIMESTAMP", "CURRENT_USER",
+	"DEFAULT", "DEFERRABLE", "DESC", "DISTINCT", "DO", "ELSE", "END",
+	"EXCEPT", "FALSE", "FETCH", "FOR", "FOREIGN", "FREEZE", "FROM", "FULL",
+	"GRANT", "GROUP", "HAVING", "ILIKE", "IN", "INITIALLY", "INNER",
+	"INTERSECT", "INTO", "IS", "ISNULL", "JOIN", "LATERAL", "LEADING", "LEFT",
+	"LIKE", "LIMIT", "LOCALTIME", "LOCALTIMESTAMP", "NATURAL", "NOT",
+	"NOTNULL", "NULL", "OFFSET", "ON", "ONLY", "OR", "ORDER", "OUTER",
+	"OVERLAPS", "PLACING", "PRIMARY", "REFERENCES", "RETURNING", "RIGHT",
+	"SELECT", "SESSION_USER", "SIMILAR", "SOME", "SYMMETRIC", "SYSTEM_USER",
+	"TABLE", "TABLESAMPLE", "THEN", "TO", "TRAILING", "TRUE", "UNION",
+	"UNIQUE", "USER", "USING", "VARIADIC", "VERBOSE", "WHEN", "WHERE",
+	"WINDOW", "WITH",
+}
+
+var glSQLiteReservedMap = reservedMap(glSQLiteReservedList)
+
+var glPostgresReservedMap = reservedMap(glPostgresReservedList)
+
+func reservedMap(list []string) (mp map[string]bool) {
+	mp = make(map[string]bool, len(list))
+	for _, str := range list {
+		mp[str] = true
+	}
+	return
+}
+
+// SQLiteReservedWords returns the keywords of the SQLite dialect in upper
+// case. A table name that matches one of these, without regard to case, is
+// enclosed in double quotes wherever it appears in generated SQL.
+func SQLiteReservedWords() []string {
+	return append([]string(nil), glSQLiteReservedList...)
+}
+
+// PostgresReservedWords returns the reserved keywords of the PostgreSQL
+// dialect in upper case.
+func PostgresReservedWords() []string {
+	return append([]string(nil), glPostgresReservedList...)
+}
@@ -0,0 +1,86 @@
+package dbmap
+
+import (
+	"database/sql"
+)
+
+// SafeWrapType is a counterpart of WrapType that is safe for concurrent use,
+// for example by the request handlers of a server. It is obtained with
+// DscType.WrapSafe(). It holds no state of its own other than the descriptor
+// and the database handle. Each of its methods performs one self-contained
+// operation with a WrapType instance of its own and returns any error
+// directly, so concurrent calls do not interfere with each other's results,
+// errors or prepared statements.
+//
+// Operations that span several calls, such as Query() followed by Next(), or
+// a series of commands within a transaction, are inherently sequential. For
+// these, Wrap() returns a WrapType instance that the calling goroutine uses
+// alone.
+type SafeWrapType struct {
+	dsc DscType
+	hnd *sql.DB
+}
+
+// WrapSafe returns a wrapper for the database handle hnd that is safe for
+// concurrent use. See SafeWrapType.
+func (dsc DscType) WrapSafe(hnd *sql.DB) SafeWrapType {
+	return SafeWrapType{dsc: dsc, hnd: hnd}
+}
+
+// Wrap returns a new WrapType instance for use by a single goroutine. Unlike
+// instances returned by WrapJoin(), it does not share transactions or errors
+// with other instances.
+func (s SafeWrapType) Wrap() WrapType {
+	return s.dsc.Wrap(s.hnd)
+}
+
+// do performs fnc with a new WrapType instance and returns its error.
+func (s SafeWrapType) do(fnc func(w *WrapType)) error {
+	w := s.Wrap()
+	fnc(&w)
+	w.Close()
+	return w.Err()
+}
+
+// Insert is like WrapType.Insert().
+func (s SafeWrapType) Insert(recPtr interface{}) error {
+	return s.do(func(w *WrapType) {
+		w.Insert(recPtr)
+	})
+}
+
+// Update is like WrapType.Update().
+func (s SafeWrapType) Update(rec interface{}, fldNames ...string) error {
+	return s.do(func(w *WrapType) {
+		w.Update(rec, fldNames...)
+	})
+}
+
+// Delete is like WrapType.Delete().
+func (s SafeWrapType) Delete(tailStr string, args ...interface{}) error {
+	return s.do(func(w *WrapType) {
+		w.Delete(tailStr, args...)
+	})
+}
+
+// QueryRow is like WrapType.QueryRow().
+func (s SafeWrapType) QueryRow(recPtr interface{}, tail interface{}, args ...interface{}) error {
+	return s.do(func(w *WrapType) {
+		w.QueryRow(recPtr, tail, args...)
+	})
+}
+
+// QueryAll is like WrapType.QueryAll().
+func (s SafeWrapType) QueryAll(slicePtr interface{}, tailStr string, args ...interface{}) error {
+	return s.do(func(w *WrapType) {
+		w.QueryAll(slicePtr, tailStr, args...)
+	})
+}
+
+// Count is like WrapType.Count().
+func (s SafeWrapType) Count(tailStr string, args ...interface{}) (count int64, err error) {
+	err = s.do(func(w *WrapType) {
+		count = w.Count(tailStr, args...)
+	})
+	return
+}
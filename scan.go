@@ -0,0 +1,173 @@
+package dbmap
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var glTimeTp = reflect.TypeOf(time.Time{})
+
+var glTimePtrTp = reflect.TypeOf(&time.Time{})
+
+// glTimeLayoutList contains the layouts that are tried, in order, when a
+// timestamp is retrieved from the database as text.
+var glTimeLayoutList = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// timeScanType is a scan target for a time.Time or *time.Time field that
+// accepts timestamps stored as text, as they are in strict tables.
+type timeScanType struct {
+	fldVl reflect.Value
+	// Store NULL in a time.Time field as the zero time
+	nullZero bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (ts timeScanType) Scan(src interface{}) (err error) {
+	var tm time.Time
+	switch v := src.(type) {
+	case nil:
+		if ts.fldVl.Kind() == reflect.Ptr || ts.nullZero {
+			ts.fldVl.Set(reflect.Zero(ts.fldVl.Type()))
+			return
+		}
+		return fmt.Errorf("cannot store NULL in field of type %s", ts.fldVl.Type().String())
+	case time.Time:
+		tm = v
+	case string:
+		tm, err = parseTime(v)
+	case []byte:
+		tm, err = parseTime(string(v))
+	default:
+		err = fmt.Errorf("cannot store value of type %T in field of type %s",
+			src, ts.fldVl.Type().String())
+	}
+	if err == nil {
+		if ts.fldVl.Kind() == reflect.Ptr {
+			ts.fldVl.Set(reflect.ValueOf(&tm))
+		} else {
+			ts.fldVl.Set(reflect.ValueOf(tm))
+		}
+	}
+	return
+}
+
+// zeroScanType is a scan target for a field tagged with "db_zero". The
+// sentinel value zero is stored in the field as the field's zero value.
+type zeroScanType struct {
+	fldVl reflect.Value
+	zero  interface{}
+}
+
+// Scan implements the sql.Scanner interface.
+func (zs zeroScanType) Scan(src interface{}) (err error) {
+	if src == nil {
+		return fmt.Errorf("cannot store NULL in field of type %s", zs.fldVl.Type().String())
+	}
+	vl := reflect.New(zs.fldVl.Type()).Elem()
+	switch vl.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n sql.NullInt64
+		err = n.Scan(src)
+		if err == nil && vl.OverflowInt(n.Int64) {
+			err = fmt.Errorf("value %d overflows field of type %s", n.Int64, vl.Type().String())
+		}
+		vl.SetInt(n.Int64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n sql.NullInt64
+		err = n.Scan(src)
+		if err == nil && (n.Int64 < 0 || vl.OverflowUint(uint64(n.Int64))) {
+			err = fmt.Errorf("value %d overflows field of type %s", n.Int64, vl.Type().String())
+		}
+		vl.SetUint(uint64(n.Int64))
+	case reflect.Float32, reflect.Float64:
+		var n sql.NullFloat64
+		err = n.Scan(src)
+		vl.SetFloat(n.Float64)
+	default:
+		var n sql.NullString
+		err = n.Scan(src)
+		vl.SetString(n.String)
+	}
+	if err == nil {
+		if vl.Interface() == zs.zero {
+			vl.Set(reflect.Zero(vl.Type()))
+		}
+		zs.fldVl.Set(vl)
+	}
+	return
+}
+
+// boolScanType is a scan target for a field of a boolean kind, including
+// named types such as `type flagType bool`. The integers 0 and 1 that SQLite
+// stores for such fields, as well as boolean values and their text forms, are
+// converted to the field's type.
+type boolScanType struct {
+	fldVl reflect.Value
+}
+
+// Scan implements the sql.Scanner interface.
+func (bs boolScanType) Scan(src interface{}) (err error) {
+	if src == nil {
+		return fmt.Errorf("cannot store NULL in field of type %s", bs.fldVl.Type().String())
+	}
+	var n sql.NullBool
+	err = n.Scan(src)
+	if err == nil {
+		bs.fldVl.SetBool(n.Bool)
+	}
+	return
+}
+
+// jsonScanType is a scan target for a field tagged with "db_json". The JSON
+// text retrieved from the database is unmarshaled into the field. NULL
+// resets the field to its zero value.
+type jsonScanType struct {
+	fldVl reflect.Value
+}
+
+// Scan implements the sql.Scanner interface.
+func (js jsonScanType) Scan(src interface{}) (err error) {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		js.fldVl.Set(reflect.Zero(js.fldVl.Type()))
+		return
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot store value of type %T as JSON in field of type %s",
+			src, js.fldVl.Type().String())
+	}
+	// Unmarshal into a fresh value so that no previous content remains
+	ptrVl := reflect.New(js.fldVl.Type())
+	err = json.Unmarshal(data, ptrVl.Interface())
+	if err == nil {
+		js.fldVl.Set(ptrVl.Elem())
+	}
+	return
+}
+
+// parseTime converts a timestamp stored as text to a time value.
+func parseTime(str string) (tm time.Time, err error) {
+	for _, layout := range glTimeLayoutList {
+		tm, err = time.Parse(layout, str)
+		if err == nil {
+			return
+		}
+	}
+	err = fmt.Errorf("cannot parse \"%s\" as a timestamp", str)
+	return
+}
@@ -0,0 +1,91 @@
+package dbmap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ScoreFunc returns an SQL expression that scores how well the column colStr
+// matches a search term. Rows with higher scores are retrieved first. termStr
+// is the search term with the LIKE wildcards % and _ escaped with a backslash,
+// suitable for use in a LIKE pattern with ESCAPE '\'. The expression must
+// refer to values by calling bind, which returns the placeholder mark for its
+// argument.
+type ScoreFunc func(colStr, termStr string, bind func(val interface{}) string) string
+
+// SearchOpt specifies how Search() and SearchStr() order their results.
+type SearchOpt struct {
+	// Rank orders the results by relevance, best match first
+	Rank bool
+	// Score scores each searched column when Rank is true; the scores of all
+	// columns are summed. DefaultScore is used if Score is nil.
+	Score ScoreFunc
+}
+
+// DefaultScore is the ScoreFunc used when none is specified in a SearchOpt.
+// A column that begins with the search term scores 2, one that otherwise
+// contains it scores 1, and any other column scores 0.
+func DefaultScore(colStr, termStr string, bind func(val interface{}) string) string {
+	return fmt.Sprintf(`(CASE WHEN %s LIKE %s ESCAPE '\' THEN 2 `+
+		`WHEN %s LIKE %s ESCAPE '\' THEN 1 ELSE 0 END)`,
+		colStr, bind(termStr+"%"), colStr, bind("%"+termStr+"%"))
+}
+
+// EscapeLike escapes the characters in str that have a special meaning in
+// LIKE patterns, namely the wildcards % and _ and the backslash itself, by
+// preceding each with a backslash. The result matches str literally when used
+// in a LIKE pattern that is followed by the clause ESCAPE '\', for example
+// "WHERE name LIKE ? ESCAPE '\'" with the parameter "%"+EscapeLike(str)+"%".
+// The ESCAPE clause is needed in SQLite, which has no default escape
+// character, and is harmless in PostgreSQL. EscapeLike does not apply to the
+// GLOB operator of SQLite, whose wildcards are *, ? and [ and which has no
+// ESCAPE clause.
+func EscapeLike(str string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(str)
+}
+
+// SearchStr returns a command string, along with its arguments, suitable for
+// retrieving the records of the table associated with the receiver in which
+// any of the columns named in cols contains termStr. The comparison is made
+// with LIKE, so it is case-insensitive for ASCII letters in SQLite; wildcard
+// characters in termStr are matched literally. If opt.Rank is true, the
+// records are ordered by relevance as scored by opt.Score. An error occurs if
+// cols is empty or if a name in cols is not the "db" tag of a field in the
+// record structure.
+func (dsc DscType) SearchStr(cols []string, termStr string, opt SearchOpt) (cmdStr string, args []interface{}, err error) {
+	if len(cols) == 0 {
+		return "", nil, errors.New("search requires at least one column")
+	}
+	for _, nameStr := range cols {
+		if _, ok := dsc.nameMap[nameStr]; !ok {
+			return "", nil, fmt.Errorf("field name \"%s\" not in structure", nameStr)
+		}
+	}
+	bind := func(val interface{}) string {
+		args = append(args, val)
+		return dsc.dialect.PlaceholderMark(len(args))
+	}
+	likeStr := EscapeLike(termStr)
+	var condList, scoreList []string
+	for _, nameStr := range cols {
+		condList = append(condList, fmt.Sprintf(`%s LIKE %s ESCAPE '\'`,
+			dsc.ident(nameStr), bind("%"+likeStr+"%")))
+	}
+	tailStr := "WHERE " + strings.Join(condList, " OR ")
+	if opt.Rank {
+		score := opt.Score
+		if score == nil {
+			score = DefaultScore
+		}
+		for _, nameStr := range cols {
+			scoreList = append(scoreList, score(dsc.ident(nameStr), likeStr, bind))
+		}
+		tailStr += " ORDER BY " + strings.Join(scoreList, " + ") + " DESC"
+		if dsc.idPresent {
+			tailStr += ", " + dsc.ident(dsc.primaryKeyColumn())
+		}
+	}
+	cmdStr = dsc.SelectStr(tailStr)
+	return
+}
@@ -0,0 +1,69 @@
+package dbmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// liveStr returns the condition that is satisfied by the records of the
+// receiver's table that have not been soft-deleted.
+func (dsc DscType) liveStr() string {
+	if dsc.soft.sf.Type.Kind() == reflect.Bool {
+		return dsc.ident(dsc.soft.nameStr) + " = " + dsc.dialect.BoolLiteral(false)
+	}
+	return dsc.ident(dsc.soft.nameStr) + " IS NULL"
+}
+
+// fromStr returns the source of records in SELECT commands. If the receiver
+// has a soft-delete column and withDeleted is false, this is a subquery that
+// excludes the soft-deleted records of the table. The subquery has the name of
+// the table so that tailStr can refer to the table's columns as usual.
+func (dsc DscType) fromStr(withDeleted bool) string {
+	if len(dsc.soft.nameStr) == 0 || withDeleted {
+		return dsc.tblIdentStr
+	}
+	colStr := "*"
+	if dsc.idPresent && len(dsc.idNameStr) == 0 && len(dsc.dialect.AutoIncrementType()) == 0 {
+		// Make the implicit primary key column available outside the subquery
+		colStr = dsc.ident(dsc.primaryKeyColumn()) + ", *"
+	}
+	return fmt.Sprintf("(SELECT %s FROM %s WHERE %s) AS %s",
+		colStr, dsc.tblIdentStr, dsc.liveStr(), dsc.tblIdentStr)
+}
+
+// SelectWithDeletedStr is like SelectStr() except that soft-deleted records
+// are included.
+func (dsc DscType) SelectWithDeletedStr(tailStr string) string {
+	return fmt.Sprintf("SELECT %s FROM %s%s;",
+		dsc.sel.nameStr, dsc.fromStr(true), prePad(tailStr))
+}
+
+// SoftDeleteArg returns the command string and arguments with which
+// WrapType.Delete() marks the records that satisfy tailStr, with the arguments
+// args, as deleted. If the receiver has no field tagged "db_softdelete", they
+// are the command string returned by DeleteStr() and args, which remove the
+// records.
+func (dsc DscType) SoftDeleteArg(tailStr string, args ...interface{}) (cmdStr string, argList []interface{}) {
+	if len(dsc.soft.nameStr) == 0 {
+		return dsc.DeleteStr(tailStr), args
+	}
+	var valStr string
+	switch dsc.soft.sf.Type {
+	case glTimeTp, glTimePtrTp:
+		tm := NowFunc()
+		if dsc.dialect.PlaceholderMark(1) == dsc.dialect.PlaceholderMark(2) {
+			// Positional placeholders: the time precedes the arguments of tailStr
+			argList = append([]interface{}{tm}, args...)
+			valStr = dsc.dialect.PlaceholderMark(1)
+		} else {
+			argList = append(append(argList, args...), tm)
+			valStr = dsc.dialect.PlaceholderMark(len(argList))
+		}
+	default:
+		argList = args
+		valStr = dsc.dialect.BoolLiteral(true)
+	}
+	cmdStr = fmt.Sprintf("UPDATE %s SET %s = %s%s;",
+		dsc.tblIdentStr, dsc.ident(dsc.soft.nameStr), valStr, prePad(tailStr))
+	return
+}
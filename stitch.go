@@ -0,0 +1,115 @@
+package dbmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// stitchField returns the descriptor of the record type of the slice elements
+// of sliceVl, along with the field of that type associated with the column
+// nameStr. The column may be the primary key column, for example "rowid".
+func stitchField(sliceVl reflect.Value, nameStr string) (dsc DscType, sf reflect.StructField, err error) {
+	recTp := sliceVl.Type().Elem()
+	if recTp.Kind() == reflect.Ptr {
+		recTp = recTp.Elem()
+	}
+	dsc, err = describe(recTp)
+	if err == nil {
+		var ok bool
+		sf, ok = dsc.nameMap[nameStr]
+		if !ok {
+			keyStr, idOk := dsc.PrimaryKey()
+			if idOk && keyStr == nameStr {
+				sf = dsc.idSf
+			} else {
+				err = fmt.Errorf("field name \"%s\" not in structure %s", nameStr, recTp.String())
+			}
+		}
+	}
+	return
+}
+
+// stitchSlice returns the slice indicated by val, which may be a slice or a
+// pointer to one.
+func stitchSlice(val interface{}) (sliceVl reflect.Value, err error) {
+	sliceVl = reflect.ValueOf(val)
+	if sliceVl.Kind() == reflect.Ptr {
+		sliceVl = sliceVl.Elem()
+	}
+	if sliceVl.Kind() != reflect.Slice {
+		err = fmt.Errorf("value of type %T must be a slice of records or a pointer to one", val)
+	}
+	return
+}
+
+// StitchHasMany assigns to each record in parents the records in children
+// that refer to it. This supports the retrieval of a one-to-many relationship
+// with two queries, for example with WrapType.QueryAll(), rather than a join.
+// parents and children are slices, or pointers to slices, of properly tagged
+// structures or of pointers to them. parentKey names the column of the parent
+// records, for example "rowid", that is referred to by the column childFK of
+// the child records. The fields associated with these columns must have the
+// same type. The parent structure must have exactly one field whose type is a
+// slice of the child slice's element type; it is set to the children of the
+// parent, in the order in which they appear in children, or to nil if there
+// are none. Children whose foreign key matches no parent are ignored.
+func StitchHasMany(parents interface{}, children interface{}, parentKey, childFK string) (err error) {
+	var parentVl, childVl reflect.Value
+	var parentSf, childSf reflect.StructField
+	var parentDsc DscType
+	parentVl, err = stitchSlice(parents)
+	if err == nil {
+		childVl, err = stitchSlice(children)
+	}
+	if err == nil {
+		parentDsc, parentSf, err = stitchField(parentVl, parentKey)
+	}
+	if err == nil {
+		_, childSf, err = stitchField(childVl, childFK)
+	}
+	if err == nil && parentSf.Type != childSf.Type {
+		err = fmt.Errorf("parent key \"%s\" of type %s does not match foreign key \"%s\" of type %s",
+			parentKey, parentSf.Type.String(), childFK, childSf.Type.String())
+	}
+	var listSf reflect.StructField
+	if err == nil {
+		listTp := reflect.SliceOf(childVl.Type().Elem())
+		var count int
+		for j := 0; j < parentDsc.recTp.NumField(); j++ {
+			sf := parentDsc.recTp.Field(j)
+			if sf.Type == listTp && len(sf.PkgPath) == 0 {
+				listSf = sf
+				count++
+			}
+		}
+		if count != 1 {
+			err = fmt.Errorf("structure %s must have exactly one exported field of type %s, found %d",
+				parentDsc.recTp.String(), listTp.String(), count)
+		}
+	}
+	if err == nil {
+		groupMap := make(map[interface{}]reflect.Value)
+		for j := 0; j < childVl.Len(); j++ {
+			vl := reflect.Indirect(childVl.Index(j))
+			if vl.IsValid() {
+				key := vl.FieldByIndex(childSf.Index).Interface()
+				list, ok := groupMap[key]
+				if !ok {
+					list = reflect.Zero(listSf.Type)
+				}
+				groupMap[key] = reflect.Append(list, childVl.Index(j))
+			}
+		}
+		for j := 0; j < parentVl.Len(); j++ {
+			vl := reflect.Indirect(parentVl.Index(j))
+			if vl.IsValid() {
+				list, ok := groupMap[vl.FieldByIndex(parentSf.Index).Interface()]
+				if !ok {
+					list = reflect.Zero(listSf.Type)
+				}
+				vl.FieldByIndex(listSf.Index).Set(list)
+			}
+		}
+	}
+	return
+}
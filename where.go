@@ -0,0 +1,316 @@
+package dbmap
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Cond is a condition that can be assembled into a WHERE clause with
+// DscType.WhereAll(). A condition with an empty expression is considered to be
+// inactive and is omitted from the clause.
+type Cond struct {
+	// SQL expression, for example "num > ?"
+	ExprStr string
+	// Parameters, one for each placeholder in ExprStr
+	Args []interface{}
+}
+
+// CondIf returns a condition made of exprStr and args if active is true, or
+// an inactive condition otherwise. This facilitates the construction of
+// filters from optional inputs.
+func CondIf(active bool, exprStr string, args ...interface{}) (c Cond) {
+	if active {
+		c.ExprStr = exprStr
+		c.Args = args
+	}
+	return
+}
+
+// WhereAll assembles the active conditions in the list conds into a WHERE
+// clause in which they are joined with AND. The returned tailStr and args can
+// be passed to methods like WrapType.Query(). If no condition is active,
+// tailStr is empty. When the receiver's dialect uses question marks as
+// placeholders, an error is returned if the number of placeholders in a
+// condition does not match its number of parameters. When the dialect numbers
+// its placeholders, the numbers refer to the combined parameters of all active
+// conditions, and an error is returned unless each parameter has a placeholder
+// and no placeholder exceeds the number of parameters.
+func (dsc DscType) WhereAll(conds ...Cond) (tailStr string, args []interface{}, err error) {
+	var list strListType
+	countPlaceholders := dsc.dialect.PlaceholderMark(1) == "?"
+	for _, c := range conds {
+		if err == nil && len(c.ExprStr) > 0 {
+			if countPlaceholders {
+				count := strings.Count(c.ExprStr, "?")
+				if count != len(c.Args) {
+					err = fmt.Errorf("condition \"%s\" has %d placeholders but %d parameters",
+						c.ExprStr, count, len(c.Args))
+				}
+			}
+			list.append(c.ExprStr)
+			args = append(args, c.Args...)
+		}
+	}
+	if err == nil {
+		switch len(list) {
+		case 0:
+		case 1:
+			tailStr = "WHERE " + list[0]
+		default:
+			tailStr = "WHERE (" + strings.Join(list, ") AND (") + ")"
+		}
+		if !countPlaceholders && dsc.dialect.PlaceholderMark(1) != dsc.dialect.PlaceholderMark(2) {
+			// Numbered placeholders refer to the combined parameters
+			for j := 1; j <= len(args)+1 && err == nil; j++ {
+				markStr := dsc.dialect.PlaceholderMark(j)
+				found := containsMark(tailStr, markStr)
+				if j <= len(args) && !found {
+					err = fmt.Errorf("clause \"%s\" has no placeholder %s for parameter %d",
+						tailStr, markStr, j)
+				} else if j > len(args) && found {
+					err = fmt.Errorf("clause \"%s\" has placeholder %s but %d parameters",
+						tailStr, markStr, len(args))
+				}
+			}
+		}
+	}
+	if err != nil {
+		tailStr = ""
+		args = nil
+	}
+	return
+}
+
+// containsMark returns true if str contains the numbered placeholder markStr
+// other than as the beginning of a longer number, as "$1" begins "$10".
+func containsMark(str, markStr string) bool {
+	for {
+		pos := strings.Index(str, markStr)
+		if pos < 0 {
+			return false
+		}
+		str = str[pos+len(markStr):]
+		if len(str) == 0 || str[0] < '0' || str[0] > '9' {
+			return true
+		}
+	}
+}
+
+// In returns a condition that tests whether the column colStr holds one of
+// the elements of the slice values, for example "num IN (?, ?, ?)", along with
+// the elements as parameters. The condition can be used in a tail string or
+// passed to DscType.Where() and TailType.And(). If values is nil or an empty
+// slice, the condition "1 = 0", which no record satisfies, is returned with no
+// parameters. A value that is not a slice, or that is a []byte, is treated as
+// a single element.
+func In(colStr string, values interface{}) (clauseStr string, args []interface{}) {
+	vl := reflect.ValueOf(values)
+	if vl.Kind() == reflect.Slice && vl.Type().Elem().Kind() != reflect.Uint8 {
+		args = make([]interface{}, vl.Len())
+		for j := range args {
+			args[j] = vl.Index(j).Interface()
+		}
+	} else if values != nil {
+		args = []interface{}{values}
+	}
+	if len(args) == 0 {
+		return "1 = 0", nil
+	}
+	clauseStr = colStr + " IN (?" + strings.Repeat(", ?", len(args)-1) + ")"
+	return
+}
+
+// Like returns a condition that tests whether the column colStr contains
+// termStr, for example "str LIKE ? ESCAPE '\'", along with the pattern as its
+// parameter. Wildcard characters in termStr are escaped with EscapeLike() so
+// that they match only themselves. As with In(), the condition can be used in
+// a tail string or passed to DscType.Where() and TailType.And(). The
+// comparison is case-insensitive for ASCII letters in SQLite and
+// case-sensitive in PostgreSQL.
+func (dsc DscType) Like(colStr, termStr string) (clauseStr string, args []interface{}) {
+	return dsc.ident(colStr) + ` LIKE ? ESCAPE '\'`, []interface{}{"%" + EscapeLike(termStr) + "%"}
+}
+
+// OrderBy returns a clause, for example "ORDER BY num DESC", that orders
+// records by the column colStr, in descending order if desc is true. An error
+// is returned if colStr is not a column of the receiver's record structure or
+// its primary key column. This permits the column to be chosen by a user
+// without exposing the command to SQL injection. The clause can follow a WHERE
+// clause in the tail strings passed to methods such as WrapType.Query().
+func (dsc DscType) OrderBy(colStr string, desc bool) (orderStr string, err error) {
+	var ok bool
+	for _, nameStr := range dsc.sel.nameList {
+		if len(nameStr) == 0 {
+			nameStr = dsc.primaryKeyColumn()
+		}
+		ok = ok || nameStr == colStr
+	}
+	if ok {
+		orderStr = "ORDER BY " + dsc.ident(colStr)
+		if desc {
+			orderStr += " DESC"
+		}
+	} else {
+		err = fmt.Errorf("\"%s\" is not a column of table %s", colStr, dsc.tblStr)
+	}
+	return
+}
+
+// Tailer is implemented by values that supply the portion of a SELECT command
+// that follows the table name, along with its parameters. TailType implements
+// it. WrapType.Query(), WrapType.QueryRow() and their context variants accept
+// a Tailer in place of a tail string.
+type Tailer interface {
+	Tail() (tailStr string, args []interface{}, err error)
+}
+
+// TailType accumulates the WHERE, ORDER BY, LIMIT and OFFSET clauses of a
+// SELECT command along with the parameters of its conditions. It is obtained
+// with DscType.Where() or DscType.Tail(). Like DscType, it is immutable: each
+// method returns a modified copy, so a partially built value can be shared
+// and extended independently.
+type TailType struct {
+	dialect Dialect
+	// {"AND", "num > ?", "OR", "str LIKE ?", ...}; connector then
+	// condition, the first connector being unused
+	condList  []string
+	args      []interface{}
+	orderStr  string
+	limitStr  string
+	offsetStr string
+	err       error
+}
+
+// Tail returns an empty TailType for use with the receiver's dialect.
+func (dsc DscType) Tail() TailType {
+	return TailType{dialect: dsc.dialect}
+}
+
+// Where returns a TailType whose WHERE clause begins with the condition
+// exprStr. Placeholders in exprStr are written as question marks, one for
+// each value in args; they are converted to the receiver's dialect when the
+// clause is generated.
+func (dsc DscType) Where(exprStr string, args ...interface{}) TailType {
+	return dsc.Tail().And(exprStr, args...)
+}
+
+// cond returns a copy of t with the condition exprStr joined to the existing
+// conditions with connStr.
+func (t TailType) cond(connStr, exprStr string, args []interface{}) TailType {
+	if t.err == nil {
+		count := strings.Count(exprStr, "?")
+		if count == len(args) {
+			t.condList = append(t.condList[:len(t.condList):len(t.condList)], connStr, exprStr)
+			t.args = append(t.args[:len(t.args):len(t.args)], args...)
+		} else {
+			t.err = fmt.Errorf("condition \"%s\" has %d placeholders but %d parameters",
+				exprStr, count, len(args))
+		}
+	}
+	return t
+}
+
+// And returns a copy of t with the condition exprStr, as described in
+// DscType.Where(), joined to the existing conditions with AND.
+func (t TailType) And(exprStr string, args ...interface{}) TailType {
+	return t.cond("AND", exprStr, args)
+}
+
+// Or returns a copy of t with the condition exprStr, as described in
+// DscType.Where(), joined to the existing conditions with OR.
+func (t TailType) Or(exprStr string, args ...interface{}) TailType {
+	return t.cond("OR", exprStr, args)
+}
+
+// OrderBy returns a copy of t that orders the records by the columns in
+// cols, each of which may be followed by ASC or DESC. It replaces any order
+// specified previously.
+func (t TailType) OrderBy(cols ...string) TailType {
+	t.orderStr = strings.Join(cols, ", ")
+	return t
+}
+
+// OrderByRandom returns a copy of t that orders the records randomly, using
+// the random function of the dialect. It replaces any order specified
+// previously.
+func (t TailType) OrderByRandom() TailType {
+	t.orderStr = t.dialectOrDefault().RandomFunc()
+	return t
+}
+
+// Limit returns a copy of t that retrieves at most count records.
+func (t TailType) Limit(count int) TailType {
+	t.limitStr = strconv.Itoa(count)
+	return t
+}
+
+// Offset returns a copy of t that skips the first count records.
+func (t TailType) Offset(count int) TailType {
+	t.offsetStr = strconv.Itoa(count)
+	return t
+}
+
+// dialectOrDefault returns the dialect of t, or SQLiteDialect if t is the
+// zero value.
+func (t TailType) dialectOrDefault() Dialect {
+	if t.dialect == nil {
+		return SQLiteDialect{}
+	}
+	return t.dialect
+}
+
+// Tail implements the Tailer interface. It returns the accumulated clauses
+// and their parameters in the order in which the placeholders appear. The
+// first error encountered while building t, if any, is returned.
+func (t TailType) Tail() (tailStr string, args []interface{}, err error) {
+	if t.err != nil {
+		return "", nil, t.err
+	}
+	var list strListType
+	switch len(t.condList) {
+	case 0:
+	case 2:
+		list = append(list, "WHERE "+t.condList[1])
+	default:
+		exprStr := "WHERE (" + t.condList[1] + ")"
+		for j := 2; j < len(t.condList); j += 2 {
+			exprStr += " " + t.condList[j] + " (" + t.condList[j+1] + ")"
+		}
+		list = append(list, exprStr)
+	}
+	if len(t.orderStr) > 0 {
+		list = append(list, "ORDER BY "+t.orderStr)
+	}
+	if len(t.limitStr) > 0 || len(t.offsetStr) > 0 {
+		list = append(list, t.dialectOrDefault().LimitClause(t.limitStr, t.offsetStr))
+	}
+	tailStr = strings.Join(list, " ")
+	if t.dialect != nil && t.dialect.PlaceholderMark(1) != "?" {
+		partList := strings.Split(tailStr, "?")
+		tailStr = partList[0]
+		for j, str := range partList[1:] {
+			tailStr += t.dialect.PlaceholderMark(j+1) + str
+		}
+	}
+	args = t.args
+	return
+}
+
+// tailArgs returns the tail string and parameters specified by tail, which is
+// a string or a Tailer, followed by args.
+func tailArgs(tail interface{}, args []interface{}) (tailStr string, argList []interface{}, err error) {
+	switch v := tail.(type) {
+	case string:
+		tailStr, argList = v, args
+	case Tailer:
+		tailStr, argList, err = v.Tail()
+		if err == nil {
+			argList = append(argList[:len(argList):len(argList)], args...)
+		}
+	default:
+		err = fmt.Errorf("tail of type %T must be a string or a Tailer", tail)
+	}
+	return
+}
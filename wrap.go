@@ -1,31 +1,169 @@
 package dbmap
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 )
 
+// bufferSizeDefault is the number of records that BufferInsert() accumulates
+// before flushing them if SetBufferSize() has not been called.
+const bufferSizeDefault = 256
+
+// stmtCacheSize is the number of prepared statements that a WrapType instance
+// retains for reuse.
+const stmtCacheSize = 16
+
+// insertParamMax is the largest number of parameters that will be bound to a
+// single multi-row insertion. This is the default value of SQLite's
+// SQLITE_MAX_VARIABLE_NUMBER.
+const insertParamMax = 999
+
 type shareType struct {
 	hnd    *sql.DB
 	tx     *sql.Tx
 	errVal error
+	// Rows affected by write operations in the active transaction
+	txRowCount int64
+	// Hook registered with SetLogger(); nil if none
+	logger LoggerFunc
+	// Settings registered with SetBusyRetry(); no retries if attempts is zero
+	busy struct {
+		attempts int
+		backoff  time.Duration
+	}
 }
 
+// LoggerFunc is called by WrapType methods after each command is submitted to
+// the database. See WrapType.SetLogger().
+type LoggerFunc func(cmdStr string, args []interface{}, dur time.Duration, err error)
+
 // WrapType facilitates the use of DscType. Since it is not safe for concurrent
 // use, it is intended for self-contained database interactions that take place
-// within a function where the WrapType variable is local.
+// within a function where the WrapType variable is local. SafeWrapType can be
+// shared by goroutines.
 type WrapType struct {
 	sharePtr *shareType
 	dsc      DscType
 	res      sql.Result
 	insert   struct {
-		st     *sql.Stmt
+		st *sql.Stmt
+		// Command of st, for the logger
+		cmdStr string
+		// Kind of insertion performed by st
+		mode insertMode
+		// Transaction in which st was prepared; nil if none
+		tx     *sql.Tx
 		idAddr interface{}
 	}
 	sel struct {
 		rows *sql.Rows
 		args []interface{}
+		// Context of the query that produced rows; nil if none was given
+		ctx context.Context
+	}
+	buffer struct {
+		size int
+		list []bufferType
+	}
+	// Prepared statements, least recently used first
+	stmtList []stmtType
+}
+
+// stmtType is a prepared statement retained for reuse by a WrapType instance.
+type stmtType struct {
+	cmdStr string
+	// Transaction in which the statement was prepared; nil if none
+	tx *sql.Tx
+	st *sql.Stmt
+}
+
+// bufferType holds the insertion arguments of a record that has been passed
+// to BufferInsert() but not yet written to the database.
+type bufferType struct {
+	args  []interface{}
+	setID func(int64)
+}
+
+// BatchError is the error retained by batch methods such as InsertBatch()
+// when one of the records in the batch cannot be processed. It identifies the
+// offending record and wraps the underlying error so that errors.Is() and
+// errors.As() can examine it.
+type BatchError struct {
+	// Zero-based position of the failing record within the batch
+	Index int
+	// Error that occurred when processing the record
+	Err error
+}
+
+// Error satisfies the error interface.
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch record %d: %s", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// ChunkError is the error retained by UpsertBatch() when one of the
+// multi-row commands into which the batch is divided fails. It identifies the
+// records of that command and wraps the underlying error.
+type ChunkError struct {
+	// Zero-based position within the batch of the chunk's first record
+	First int
+	// Number of records in the chunk
+	Count int
+	// Error that occurred when processing the chunk
+	Err error
+}
+
+// Error satisfies the error interface.
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("batch records %d to %d: %s", e.First, e.First+e.Count-1, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// OpError is the error retained by operations such as Insert(), Update(),
+// Delete(), Query() and Create() when they fail. It identifies the operation
+// and the table and wraps the underlying error, for example sql.ErrNoRows or
+// a driver error, so that errors.Is() and errors.As() can examine it.
+type OpError struct {
+	// Operation, for example "insert into" or "query"
+	Op string
+	// Name of the table associated with the descriptor
+	Table string
+	// Error that occurred during the operation
+	Err error
+}
+
+// Error satisfies the error interface.
+func (e *OpError) Error() string {
+	return fmt.Sprintf("dbmap %s %s: %s", e.Op, e.Table, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// annotate wraps the retained error in an *OpError for the operation opStr.
+// fresh reports whether no error was retained when the operation began; an
+// error that precedes the operation, or that a nested operation has already
+// wrapped, is left as it is.
+func (w *WrapType) annotate(opStr string, fresh bool) {
+	var opErr *OpError
+	if fresh && w.sharePtr.errVal != nil && !errors.As(w.sharePtr.errVal, &opErr) {
+		w.sharePtr.errVal = &OpError{Op: opStr, Table: w.dsc.tblStr, Err: w.sharePtr.errVal}
 	}
 }
 
@@ -34,6 +172,97 @@ func (w *WrapType) String() string {
 	return "dbmap/wrap"
 }
 
+// SetLogger registers fnc to be called after each command that the receiver,
+// or any WrapType instance that shares its transactions by way of
+// WrapJoin(), prepares, executes or submits as a query. It receives the
+// command string, the arguments bound to it (nil for preparation), the time
+// the operation took and the error it returned. The error is reported even if
+// it is subsequently retained as described in the package documentation. For
+// queries, the time does not include the retrieval of rows. A value of nil
+// removes the logger.
+func (w *WrapType) SetLogger(fnc LoggerFunc) {
+	w.sharePtr.logger = fnc
+}
+
+// SetBusyRetry arranges for a command that fails because another connection
+// has locked the database, as SQLite reports with SQLITE_BUSY, to be submitted
+// again up to attempts times before the error is retained. The pause before
+// the first retry is backoff and it doubles with each subsequent retry. Since
+// waiting for a lock within a transaction can deadlock, commands submitted
+// within a transaction are not retried. Errors that occur while rows are
+// retrieved are not retried either. Like SetLogger(), the setting applies to
+// the receiver and to WrapType instances that share its transactions by way of
+// WrapJoin(). A value of zero for attempts, the default, disables retries.
+func (w *WrapType) SetBusyRetry(attempts int, backoff time.Duration) {
+	w.sharePtr.busy.attempts = attempts
+	w.sharePtr.busy.backoff = backoff
+}
+
+// busyError reports whether err indicates that the database is locked by
+// another connection. The error is recognized by its text since the error
+// types of drivers are not available to this package.
+func busyError(err error) bool {
+	str := strings.ToLower(err.Error())
+	for _, lockStr := range []string{"database is locked", "database table is locked",
+		"sqlite_busy", "sqlite_locked"} {
+		if strings.Contains(str, lockStr) {
+			return true
+		}
+	}
+	return false
+}
+
+// busyWait returns true, after pausing as described in SetBusyRetry(), if the
+// command that failed with err on the attempt numbered from zero should be
+// submitted again. False is returned immediately if err is nil, if no
+// retry is permitted or if ctx is done.
+func (w *WrapType) busyWait(ctx context.Context, err error, attempt int) bool {
+	if err == nil || attempt >= w.sharePtr.busy.attempts || w.sharePtr.tx != nil || !busyError(err) {
+		return false
+	}
+	tm := time.NewTimer(w.sharePtr.busy.backoff << uint(attempt))
+	select {
+	case <-tm.C:
+		return true
+	case <-ctx.Done():
+		tm.Stop()
+		return false
+	}
+}
+
+// retry calls fn, which submits cmdStr with args, and reports the command to
+// the logger. If fn fails because the database is locked, it is called again
+// as described in SetBusyRetry(). The error returned by the last call is
+// returned.
+func (w *WrapType) retry(ctx context.Context, cmdStr string, args []interface{},
+	fn func() error) (err error) {
+	for attempt := 0; ; attempt++ {
+		start := w.traceStart()
+		err = fn()
+		w.trace(start, cmdStr, args, err)
+		if !w.busyWait(ctx, err, attempt) {
+			return
+		}
+	}
+}
+
+// traceStart returns the start time of an operation that is reported to the
+// logger, or the zero time if no logger is registered.
+func (w *WrapType) traceStart() (tm time.Time) {
+	if w.sharePtr.logger != nil {
+		tm = time.Now()
+	}
+	return
+}
+
+// trace reports the command cmdStr, begun at start, to the logger if one is
+// registered.
+func (w *WrapType) trace(start time.Time, cmdStr string, args []interface{}, err error) {
+	if w.sharePtr.logger != nil {
+		w.sharePtr.logger(cmdStr, args, time.Since(start), err)
+	}
+}
+
 // Tx returns the currently registered SQL transaction. This is nil if no
 // transaction is active.
 func (w *WrapType) Tx() *sql.Tx {
@@ -69,6 +298,34 @@ func (dsc DscType) Wrap(hnd *sql.DB) (w WrapType) {
 	return
 }
 
+// Open opens the database identified by driverStr and dsnStr, as
+// sql.Open() does, and verifies the connection with a ping so that a
+// connection error is reported immediately rather than by the first command.
+// The caller is responsible for closing the returned handle.
+func Open(driverStr, dsnStr string) (hnd *sql.DB, err error) {
+	hnd, err = sql.Open(driverStr, dsnStr)
+	if err == nil {
+		err = hnd.Ping()
+		if err != nil {
+			hnd.Close()
+			hnd = nil
+		}
+	}
+	return
+}
+
+// Connect opens the database with Open() and wraps the handle as Wrap()
+// does. The handle remains available from DB(); the caller is responsible for
+// closing it when the returned instance is no longer needed.
+func (dsc DscType) Connect(driverStr, dsnStr string) (w WrapType, err error) {
+	var hnd *sql.DB
+	hnd, err = Open(driverStr, dsnStr)
+	if err == nil {
+		w = dsc.Wrap(hnd)
+	}
+	return
+}
+
 // WrapJoin instantiates a variable to assist with database activities. It is
 // used when multiple WrapType instances need to share the database handle,
 // transactions and error handling. The execution of this method is
@@ -80,10 +337,13 @@ func (dsc DscType) WrapJoin(masterWrap WrapType) (w WrapType) {
 	return
 }
 
-// TransactionBegin start a database transaction.
+// TransactionBegin start a database transaction. Transactions cannot be
+// nested; use SavepointBegin() to mark a point within the active transaction
+// to which changes can be rolled back.
 func (w *WrapType) TransactionBegin() {
 	if w.sharePtr.errVal == nil {
 		if w.sharePtr.tx == nil {
+			w.sharePtr.txRowCount = 0
 			w.sharePtr.tx, w.sharePtr.errVal = w.sharePtr.hnd.Begin()
 		} else {
 			w.sharePtr.errVal = errors.New("nested transactions not supported")
@@ -93,17 +353,37 @@ func (w *WrapType) TransactionBegin() {
 
 func (w *WrapType) transactionEnd(commit bool) {
 	if w.sharePtr.tx != nil {
+		w.releaseTx(w.sharePtr.tx)
 		if commit {
 			w.sharePtr.tx.Commit()
 		} else {
 			w.sharePtr.tx.Rollback()
 		}
 		w.sharePtr.tx = nil
+		w.sharePtr.txRowCount = 0
 	} else if w.sharePtr.errVal == nil {
 		w.sharePtr.errVal = errors.New("no transaction to end")
 	}
 }
 
+// releaseTx closes the prepared statements of the receiver that belong to the
+// transaction tx. Such statements cannot be used once the transaction ends.
+func (w *WrapType) releaseTx(tx *sql.Tx) {
+	if w.insert.st != nil && w.insert.tx == tx {
+		w.insert.st.Close()
+		w.insert.st = nil
+	}
+	list := w.stmtList[:0]
+	for _, s := range w.stmtList {
+		if s.tx == tx {
+			s.st.Close()
+		} else {
+			list = append(list, s)
+		}
+	}
+	w.stmtList = list
+}
+
 // TransactionEnd completes a database transaction. If no error has occurred,
 // the the transaction is committed, otherwise rolled back.
 func (w *WrapType) TransactionEnd() {
@@ -120,35 +400,174 @@ func (w *WrapType) TransactionRollback() {
 	w.transactionEnd(false)
 }
 
+// savepoint executes the savepoint command made of cmdStr and nameStr within
+// the active transaction. An error occurs if no transaction is active or if
+// nameStr is not a valid identifier.
+func (w *WrapType) savepoint(cmdStr, nameStr string) {
+	if w.sharePtr.tx == nil {
+		w.sharePtr.errVal = fmt.Errorf("%s %s requires an active transaction", cmdStr, nameStr)
+	} else if !glIdentRe.MatchString(nameStr) {
+		w.sharePtr.errVal = fmt.Errorf("invalid savepoint name: %s", nameStr)
+	} else {
+		w.exec(context.Background(), fmt.Sprintf("%s %s;", cmdStr, w.dsc.ident(nameStr)))
+	}
+}
+
+// SavepointBegin establishes the savepoint nameStr within the active
+// transaction. Changes made after this call can be undone with
+// SavepointRollback() without ending the transaction. Savepoints can be
+// nested. An error occurs if no transaction is active.
+func (w *WrapType) SavepointBegin(nameStr string) {
+	if w.sharePtr.errVal == nil {
+		w.savepoint("SAVEPOINT", nameStr)
+	}
+}
+
+// SavepointRelease discards the savepoint nameStr, and any savepoints
+// established after it, while keeping the changes made since it was
+// established. The changes remain subject to the outcome of the enclosing
+// transaction.
+func (w *WrapType) SavepointRelease(nameStr string) {
+	if w.sharePtr.errVal == nil {
+		w.savepoint("RELEASE SAVEPOINT", nameStr)
+	}
+}
+
+// SavepointRollback undoes the changes made since the savepoint nameStr was
+// established. The savepoint and the enclosing transaction remain active. Like
+// TransactionRollback(), this is done even if an error has occurred; the
+// error is retained and can be cleared with ClearError() in order to continue
+// the transaction.
+func (w *WrapType) SavepointRollback(nameStr string) {
+	errVal := w.sharePtr.errVal
+	w.sharePtr.errVal = nil
+	w.savepoint("ROLLBACK TO SAVEPOINT", nameStr)
+	if errVal != nil {
+		w.sharePtr.errVal = errVal
+	}
+}
+
 // Result returns the result of the most recent database operation that does
 // not return rows. The return value can be used to retrieve the number of
-// affected rows and the most recently inserted ID.
+// affected rows and the most recently inserted ID. With a dialect that
+// retrieves identifiers with a RETURNING clause, insertions leave the result
+// nil.
 func (w *WrapType) Result() sql.Result {
 	return w.res
 }
 
-// InsertClear prepares the wrap instance for calls to Insert().
+// RowsAffected returns the number of rows affected by the most recent
+// insertion, update or deletion performed by the receiver. It spares the
+// caller the handling of the sql.Result returned by Result(). An error is
+// retained, and zero returned, if the driver does not report the number or if
+// no such command has been executed. Zero is also returned if an error is
+// already retained. To count the records that satisfy a condition, use
+// Count().
+func (w *WrapType) RowsAffected() (count int64) {
+	if w.sharePtr.errVal == nil {
+		if w.res == nil {
+			w.sharePtr.errVal = errors.New("no command has been executed that affects rows")
+		} else {
+			count, w.sharePtr.errVal = w.res.RowsAffected()
+		}
+	}
+	return
+}
+
+// InsertClear releases the statement prepared by Insert(), InsertOrReplace()
+// or InsertIgnore(). A call is not needed when switching between these
+// methods, since the statement is prepared again when the method changes. A
+// statement prepared within a transaction is released automatically when the
+// transaction ends, so a call is not needed for that purpose either.
 func (w *WrapType) InsertClear() {
+	if w.insert.st != nil {
+		w.insert.st.Close()
+	}
 	w.insert.st = nil
 }
 
-// Insert adds the record pointed to by recPtr to the database. If a unique
-// constraint is violated by the insertion and replace is true, the record will
-// be relaced.
-func (w *WrapType) insertOrReplace(recPtr interface{}, replace bool) {
+// insertExec executes the prepared insertion statement st with args and, if
+// idFnc is not nil, passes it the identifier assigned to the new record. If
+// ignore is true, the command may skip the record, in which case idFnc is not
+// called.
+func (w *WrapType) insertExec(ctx context.Context, st *sql.Stmt, cmdStr string, args []interface{},
+	idFnc func(int64), ignore bool) (err error) {
+	var id int64
+	if len(w.dsc.returningStr()) > 0 {
+		w.res = nil
+		err = w.retry(ctx, cmdStr, args, func() error {
+			return st.QueryRowContext(ctx, args...).Scan(&id)
+		})
+		if err == sql.ErrNoRows && ignore {
+			err = nil
+			idFnc = nil
+		} else if err == nil && w.sharePtr.tx != nil {
+			w.sharePtr.txRowCount++
+		}
+	} else {
+		err = w.retry(ctx, cmdStr, args, func() (err error) {
+			w.res, err = st.ExecContext(ctx, args...)
+			return
+		})
+		if err == nil {
+			w.accumulate()
+			if idFnc != nil && ignore {
+				var count int64
+				count, err = w.res.RowsAffected()
+				if count == 0 {
+					// Record skipped; the last insertion ID belongs to another record
+					idFnc = nil
+				}
+			}
+			if err == nil && idFnc != nil {
+				id, err = w.res.LastInsertId()
+			}
+		}
+	}
+	if err == nil && idFnc != nil {
+		idFnc(id)
+	}
+	return
+}
+
+// insertMode identifies the insertion command used by insertRec().
+type insertMode int
+
+const (
+	insertPlain insertMode = iota
+	insertReplace
+	insertIgnore
+)
+
+// insertRec adds the record pointed to by recPtr to the database with the
+// insertion command identified by mode. The prepared statement is retained
+// for subsequent calls with the same mode.
+func (w *WrapType) insertRec(ctx context.Context, recPtr interface{}, mode insertMode) {
+	defer w.annotate("insert into", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
 	if w.sharePtr.errVal == nil {
+		if w.insert.st != nil && (w.insert.tx != w.sharePtr.tx || w.insert.mode != mode) {
+			// Prepared in another transaction, or outside of the active one,
+			// or for another kind of insertion
+			w.insert.st.Close()
+			w.insert.st = nil
+		}
 		if w.insert.st == nil {
 			var cmdStr string
-			if replace {
+			switch mode {
+			case insertReplace:
 				cmdStr = w.dsc.InsertOrReplaceStr()
-			} else {
+			case insertIgnore:
+				cmdStr = w.dsc.InsertOrIgnoreStr()
+			default:
 				cmdStr = w.dsc.InsertStr()
 			}
-			if w.sharePtr.tx == nil {
-				w.insert.st, w.sharePtr.errVal = w.sharePtr.hnd.Prepare(cmdStr)
-			} else {
-				w.insert.st, w.sharePtr.errVal = w.sharePtr.tx.Prepare(cmdStr)
-			}
+			w.insert.st = w.prepare(ctx, cmdStr)
+			w.insert.cmdStr = cmdStr
+			w.insert.mode = mode
+			w.insert.tx = w.sharePtr.tx
 		}
 		if w.sharePtr.errVal == nil {
 			if w.insert.st != nil {
@@ -156,16 +575,8 @@ func (w *WrapType) insertOrReplace(recPtr interface{}, replace bool) {
 				var idFnc func(int64)
 				args, idFnc, w.sharePtr.errVal = w.dsc.InsertArg(recPtr)
 				if w.sharePtr.errVal == nil {
-					w.res, w.sharePtr.errVal = w.insert.st.Exec(args...)
-					if w.sharePtr.errVal == nil {
-						if idFnc != nil {
-							var id int64
-							id, w.sharePtr.errVal = w.res.LastInsertId()
-							if w.sharePtr.errVal == nil {
-								idFnc(id)
-							}
-						}
-					}
+					w.sharePtr.errVal = w.insertExec(ctx, w.insert.st, w.insert.cmdStr, args, idFnc,
+						mode == insertIgnore)
 				}
 			}
 		}
@@ -176,7 +587,62 @@ func (w *WrapType) insertOrReplace(recPtr interface{}, replace bool) {
 // structure contains an ID field tagged with db_primary, this field will be
 // assigned an identifier by the database.
 func (w *WrapType) Insert(recPtr interface{}) {
-	w.insertOrReplace(recPtr, false)
+	w.insertRec(context.Background(), recPtr, insertPlain)
+}
+
+// InsertContext is like Insert() but uses ctx for the database operations.
+func (w *WrapType) InsertContext(ctx context.Context, recPtr interface{}) {
+	w.insertRec(ctx, recPtr, insertPlain)
+}
+
+// InsertReturning is like Insert() except that the fields of the record
+// pointed to by recPtr that are associated with the cols columns are also set
+// to the values stored by the database. This captures values that the database
+// assigns, for example column defaults. With a dialect that retrieves
+// identifiers with a RETURNING clause, the values are returned by the
+// insertion command itself (see DscType.InsertReturningStr()). Otherwise, as
+// with SQLite, the identifier is obtained from sql.Result.LastInsertId() and
+// the values are retrieved with a subsequent query.
+func (w *WrapType) InsertReturning(recPtr interface{}, cols ...string) {
+	defer w.annotate("insert into", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		var colArgs, args []interface{}
+		var idFnc func(int64)
+		var id int64
+		colArgs, w.sharePtr.errVal = w.dsc.SelectColsArg(recPtr, cols)
+		if w.sharePtr.errVal == nil {
+			args, idFnc, w.sharePtr.errVal = w.dsc.InsertArg(recPtr)
+		}
+		if w.sharePtr.errVal == nil {
+			ctx := context.Background()
+			if len(w.dsc.dialect.ReturningID()) > 0 {
+				w.res = nil
+				if w.dsc.idPresent {
+					colArgs = append([]interface{}{&id}, colArgs...)
+				}
+				w.sharePtr.errVal = w.queryRow(ctx, w.dsc.InsertReturningStr(cols), args...).Scan(colArgs...)
+				if w.sharePtr.errVal == nil && w.sharePtr.tx != nil {
+					w.sharePtr.txRowCount++
+				}
+			} else {
+				w.exec(ctx, w.dsc.InsertStr(), args...)
+				if w.sharePtr.errVal == nil {
+					id, w.sharePtr.errVal = w.res.LastInsertId()
+				}
+				if w.sharePtr.errVal == nil {
+					tailStr := fmt.Sprintf("WHERE %s = %s",
+						w.dsc.ident(w.dsc.primaryKeyColumn()), w.dsc.dialect.PlaceholderMark(1))
+					w.sharePtr.errVal = w.queryRow(ctx, w.dsc.SelectColsStr(cols, tailStr), id).Scan(colArgs...)
+				}
+			}
+			if w.sharePtr.errVal == nil && idFnc != nil {
+				idFnc(id)
+			}
+		}
+	}
 }
 
 // InsertOrReplace adds the record pointed to by recPtr to the database. If the
@@ -184,135 +650,1482 @@ func (w *WrapType) Insert(recPtr interface{}) {
 // replaced. If the record structure contains an ID field tagged with
 // db_primary, this field will be assigned an identifier by the database.
 func (w *WrapType) InsertOrReplace(recPtr interface{}) {
-	w.insertOrReplace(recPtr, true)
+	w.insertRec(context.Background(), recPtr, insertReplace)
 }
 
-// Update stores the passed-in value to the database. rec must be a properly
-// tagged structure variable or a pointer to one. The structure must be one
-// that has an ID field tagged with db_primary. Furthermore, this field must
-// hold a valid ID, typically the same value the record had when it was
-// retrieved with a call to Select(). fldNames is a list of names of the
-// particular tagged fields to update. If the first name is "*", or the list is
-// entirely missing, all tagged fields are stored.
-func (w *WrapType) Update(rec interface{}, fldNames ...string) {
+// InsertIgnore adds the record pointed to by recPtr to the database unless the
+// insertion would violate a unique constraint on the table, in which case the
+// record is skipped without error. RowsAffected() then returns zero, except
+// with a dialect that retrieves identifiers with a RETURNING clause. If the
+// record structure contains an ID field tagged with db_primary, this field
+// will be assigned an identifier by the database if the record is inserted
+// and left unchanged otherwise.
+func (w *WrapType) InsertIgnore(recPtr interface{}) {
+	w.insertRec(context.Background(), recPtr, insertIgnore)
+}
+
+// InsertCols is like Insert() except that only the fields associated with the
+// cols columns, and the timestamp fields maintained by this package, are
+// stored. The database assigns the other columns their default values; the
+// corresponding fields of the record are left unchanged. Use InsertReturning()
+// to retrieve them. See DscType.InsertColsStr() for details.
+func (w *WrapType) InsertCols(recPtr interface{}, cols ...string) {
+	defer w.annotate("insert into", w.sharePtr.errVal == nil)
 	if w.sharePtr.errVal == nil {
-		cmdStr := w.dsc.UpdateStr(fldNames...)
-		var st *sql.Stmt
-		if w.sharePtr.tx == nil {
-			st, w.sharePtr.errVal = w.sharePtr.hnd.Prepare(cmdStr)
-		} else {
-			st, w.sharePtr.errVal = w.sharePtr.tx.Prepare(cmdStr)
-		}
+		var args []interface{}
+		var idFnc func(int64)
+		args, idFnc, w.sharePtr.errVal = w.dsc.InsertColsArg(recPtr, cols...)
 		if w.sharePtr.errVal == nil {
-			var args []interface{}
-			args, w.sharePtr.errVal = w.dsc.UpdateArg(rec, fldNames...)
+			ctx := context.Background()
+			cmdStr := w.dsc.InsertColsStr(cols...)
+			st := w.prepareCached(ctx, cmdStr)
 			if w.sharePtr.errVal == nil {
-				w.res, w.sharePtr.errVal = st.Exec(args...)
+				w.sharePtr.errVal = w.insertExec(ctx, st, cmdStr, args, idFnc, false)
 			}
 		}
 	}
 }
 
-// Create adds a new table and indexes of the type associated with the receiver.
-func (w *WrapType) Create() {
+// InsertOutbox inserts the event record pointed to by eventPtr into the
+// outbox table described by outboxDsc within the receiver's active
+// transaction. This supports the transactional outbox pattern: the event is
+// committed or rolled back together with the other changes made in the
+// transaction, so that it is published only if those changes persist. An
+// error occurs if no transaction is active.
+func (w *WrapType) InsertOutbox(outboxDsc DscType, eventPtr interface{}) {
 	if w.sharePtr.errVal == nil {
-		cmdStr, idxList := w.dsc.CreateStr()
-		if w.sharePtr.tx == nil {
-			_, w.sharePtr.errVal = w.sharePtr.hnd.Exec(cmdStr)
-			for _, cmdStr = range idxList {
-				if w.sharePtr.errVal == nil {
-					_, w.sharePtr.errVal = w.sharePtr.hnd.Exec(cmdStr)
-				}
+		if w.sharePtr.tx != nil {
+			ow := outboxDsc.WrapJoin(*w)
+			ow.Insert(eventPtr)
+			if ow.insert.st != nil {
+				ow.insert.st.Close()
 			}
+			w.res = ow.res
 		} else {
-			_, w.sharePtr.errVal = w.sharePtr.tx.Exec(cmdStr)
-			for _, cmdStr = range idxList {
-				if w.sharePtr.errVal == nil {
-					_, w.sharePtr.errVal = w.sharePtr.tx.Exec(cmdStr)
-				}
-			}
+			w.sharePtr.errVal = errors.New("outbox insertion requires an active transaction")
 		}
 	}
 }
 
-// Delete removes database rows that satisfy the WHERE clause in tailStr. For
-// each question mark in tailStr, there must be an appropriate parameter in the
-// args list. If tailStr is empty and args not passed, all records in the table
-// will be deleted.
-func (w *WrapType) Delete(tailStr string, args ...interface{}) {
+// exec executes cmdStr, within the active transaction if there is one, and
+// stores the result.
+func (w *WrapType) exec(ctx context.Context, cmdStr string, args ...interface{}) {
+	w.sharePtr.errVal = w.retry(ctx, cmdStr, args, func() (err error) {
+		if w.sharePtr.tx == nil {
+			w.res, err = w.sharePtr.hnd.ExecContext(ctx, cmdStr, args...)
+		} else {
+			w.res, err = w.sharePtr.tx.ExecContext(ctx, cmdStr, args...)
+		}
+		return
+	})
 	if w.sharePtr.errVal == nil {
-		cmdStr := fmt.Sprintf("DELETE FROM %s%s;", w.dsc.tblStr, prePad(tailStr))
-		w.res, w.sharePtr.errVal = w.sharePtr.hnd.Exec(cmdStr, args...)
+		w.accumulate()
 	}
 }
 
-// QueryRow submits a SELECT command to the database. recPtr must be a pointer
-// to a properly tagged structure variable. tailStr contains the portion of the
-// SELECT command that filters and orders the results. tailStr should be
-// constructed so that at most one row is returned. This may involve including
-// a LIMIT clause in it. For each question mark in tailStr, there must be an
-// appropriate parameter in the args list. This command is self-contained; it
-// is an error to use it in conjunction with Next().
-func (w *WrapType) QueryRow(recPtr interface{}, tailStr string, args ...interface{}) {
+// accumulate adds the number of rows affected by the most recent write
+// operation to the transaction total if a transaction is active.
+func (w *WrapType) accumulate() {
+	if w.sharePtr.tx != nil {
+		count, err := w.res.RowsAffected()
+		if err == nil {
+			w.sharePtr.txRowCount += count
+		}
+	}
+}
+
+// TransactionRowsAffected returns the total number of rows affected by the
+// insertions, updates and deletions that have been performed since the active
+// transaction was begun. This includes operations performed by all WrapType
+// instances that share the transaction by way of WrapJoin(). The total is
+// reset when a transaction begins and when it ends, so this method needs to
+// be called before TransactionEnd() to obtain the total of a transaction.
+func (w *WrapType) TransactionRowsAffected() int64 {
+	return w.sharePtr.txRowCount
+}
+
+// query submits cmdStr, within the active transaction if there is one, and
+// returns the resulting rows.
+func (w *WrapType) query(ctx context.Context, cmdStr string, args ...interface{}) (rows *sql.Rows) {
+	w.sharePtr.errVal = w.retry(ctx, cmdStr, args, func() (err error) {
+		if w.sharePtr.tx == nil {
+			rows, err = w.sharePtr.hnd.QueryContext(ctx, cmdStr, args...)
+		} else {
+			rows, err = w.sharePtr.tx.QueryContext(ctx, cmdStr, args...)
+		}
+		return
+	})
+	return
+}
+
+// queryRecords is like query() except that, if the number of columns
+// returned does not match count, the number of scan targets for a record, the
+// rows are closed and a descriptive error is retained. This happens, for
+// example, when a view does not provide the columns of the record.
+func (w *WrapType) queryRecords(ctx context.Context, cmdStr string, count int,
+	args ...interface{}) (rows *sql.Rows) {
+	rows = w.query(ctx, cmdStr, args...)
 	if w.sharePtr.errVal == nil {
-		var fldList []interface{}
-		fldList, w.sharePtr.errVal = w.dsc.SelectArg(recPtr)
-		if w.sharePtr.errVal == nil {
-			cmdStr := w.dsc.SelectStr(tailStr)
-			var row *sql.Row
-			if w.sharePtr.tx == nil {
-				row = w.sharePtr.hnd.QueryRow(cmdStr, args...)
+		var colList []string
+		colList, w.sharePtr.errVal = rows.Columns()
+		if w.sharePtr.errVal == nil && len(colList) != count {
+			w.sharePtr.errVal = fmt.Errorf("query returns %d columns but record expects %d",
+				len(colList), count)
+		}
+		if w.sharePtr.errVal != nil {
+			rows.Close()
+			rows = nil
+		}
+	}
+	return
+}
+
+// queryRow submits cmdStr, within the active transaction if there is one,
+// and returns the resulting row.
+func (w *WrapType) queryRow(ctx context.Context, cmdStr string, args ...interface{}) (row *sql.Row) {
+	w.retry(ctx, cmdStr, args, func() error {
+		if w.sharePtr.tx == nil {
+			row = w.sharePtr.hnd.QueryRowContext(ctx, cmdStr, args...)
+		} else {
+			row = w.sharePtr.tx.QueryRowContext(ctx, cmdStr, args...)
+		}
+		return row.Err()
+	})
+	return
+}
+
+// prepare creates a prepared statement for cmdStr, within the active
+// transaction if there is one.
+func (w *WrapType) prepare(ctx context.Context, cmdStr string) (st *sql.Stmt) {
+	start := w.traceStart()
+	if w.sharePtr.tx == nil {
+		st, w.sharePtr.errVal = w.sharePtr.hnd.PrepareContext(ctx, cmdStr)
+	} else {
+		st, w.sharePtr.errVal = w.sharePtr.tx.PrepareContext(ctx, cmdStr)
+	}
+	w.trace(start, cmdStr, nil, w.sharePtr.errVal)
+	return
+}
+
+// prepareCached returns a prepared statement for cmdStr, within the active
+// transaction if there is one. A statement prepared earlier for the same
+// command and transaction is reused. Statements of transactions that have
+// ended are discarded, and the least recently used statement is closed when
+// the cache is full.
+func (w *WrapType) prepareCached(ctx context.Context, cmdStr string) (st *sql.Stmt) {
+	list := w.stmtList[:0]
+	for _, s := range w.stmtList {
+		if s.tx == nil || s.tx == w.sharePtr.tx {
+			if s.cmdStr == cmdStr && s.tx == w.sharePtr.tx {
+				st = s.st
 			} else {
-				row = w.sharePtr.tx.QueryRow(cmdStr, args...)
+				list = append(list, s)
 			}
-			w.sharePtr.errVal = row.Scan(fldList...)
+		} else {
+			s.st.Close()
 		}
 	}
+	w.stmtList = list
+	if st == nil {
+		st = w.prepare(ctx, cmdStr)
+		if w.sharePtr.errVal != nil {
+			return nil
+		}
+		if len(w.stmtList) >= stmtCacheSize {
+			w.stmtList[0].st.Close()
+			w.stmtList = append(w.stmtList[:0], w.stmtList[1:]...)
+		}
+	}
+	w.stmtList = append(w.stmtList, stmtType{cmdStr: cmdStr, tx: w.sharePtr.tx, st: st})
+	return
 }
 
-// Query submits a SELECT command to the database. recPtr must be a pointer to
-// a properly tagged structure variable. tailStr contains the portion of the
-// SELECT command that filters and orders the results. For each question mark
-// in tailStr, there must be an appropriate parameter in the args list. If
-// tailStr is empty and args not passed, all records in the table will be
-// selected. This command works in conjunction with Next().
-func (w *WrapType) Query(recPtr interface{}, tailStr string, args ...interface{}) {
+// execCached executes cmdStr with a statement obtained from prepareCached()
+// and stores the result.
+func (w *WrapType) execCached(ctx context.Context, cmdStr string, args ...interface{}) {
+	st := w.prepareCached(ctx, cmdStr)
 	if w.sharePtr.errVal == nil {
-		w.sel.args, w.sharePtr.errVal = w.dsc.SelectArg(recPtr)
+		w.sharePtr.errVal = w.retry(ctx, cmdStr, args, func() (err error) {
+			w.res, err = st.ExecContext(ctx, args...)
+			return
+		})
+		if w.sharePtr.errVal == nil {
+			w.accumulate()
+		}
+	}
+}
+
+// Close releases the prepared statements retained by the receiver, including
+// the one used by Insert(), and closes the result set of a query whose rows
+// have not all been retrieved. It should be called when the receiver is no
+// longer needed. The receiver may still be used afterward; statements are
+// then prepared again as needed. Close does not close the database handle or
+// end the active transaction.
+func (w *WrapType) Close() {
+	w.CloseRows()
+	for _, s := range w.stmtList {
+		s.st.Close()
+	}
+	w.stmtList = nil
+	if w.insert.st != nil {
+		w.insert.st.Close()
+		w.insert.st = nil
+	}
+}
+
+// Upsert inserts the record pointed to by recPtr or, if a record with the
+// same values in the conflictCols columns already exists, updates the
+// updateCols columns of that record. If the record structure contains an ID
+// field tagged with db_primary, this field is assigned the identifier of the
+// inserted or updated record. See DscType.UpsertStr().
+func (w *WrapType) Upsert(recPtr interface{}, conflictCols []string, updateCols []string) {
+	defer w.annotate("insert into", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		var args []interface{}
+		var idFnc func(int64)
+		args, idFnc, w.sharePtr.errVal = w.dsc.UpsertArg(recPtr, conflictCols, updateCols)
 		if w.sharePtr.errVal == nil {
-			cmdStr := w.dsc.SelectStr(tailStr)
-			if w.sharePtr.tx == nil {
-				w.sel.rows, w.sharePtr.errVal = w.sharePtr.hnd.Query(cmdStr, args...)
+			ctx := context.Background()
+			cmdStr := w.dsc.UpsertStr(conflictCols, updateCols)
+			if w.dsc.idPresent {
+				var id int64
+				w.res = nil
+				w.sharePtr.errVal = w.queryRow(ctx, cmdStr, args...).Scan(&id)
+				if w.sharePtr.errVal == nil {
+					if w.sharePtr.tx != nil {
+						w.sharePtr.txRowCount++
+					}
+					if idFnc != nil {
+						idFnc(id)
+					}
+				}
 			} else {
-				w.sel.rows, w.sharePtr.errVal = w.sharePtr.tx.Query(cmdStr, args...)
+				w.exec(ctx, cmdStr, args...)
 			}
 		}
 	}
 }
 
-// Next retrieves the next row in the result set generated with a call to
-// Query(). Each row in turn is copied to the record variable pointed to the
-// recPtr argument in Query(). This method should be called repeatedly until it
-// returns false. This happens when there are no more rows to retrieve or an
-// error occurs.
-func (w *WrapType) Next() bool {
+// InsertBatch adds each record in the slice recs to the database using a
+// single prepared statement within the active transaction, if any. The slice
+// elements may be properly tagged structure variables or pointers to them. If
+// the record structure contains an ID field tagged with db_primary, this
+// field is assigned an identifier by the database in each slice element (or,
+// for a slice of pointers, in each record pointed to).
+//
+// If a record cannot be inserted, the retained error is a *BatchError that
+// identifies the position of the record within recs. Records that precede it
+// have been inserted; wrap the call in a transaction to make the batch
+// atomic.
+func (w *WrapType) InsertBatch(recs interface{}) {
+	defer w.annotate("insert into", w.sharePtr.errVal == nil)
 	if w.sharePtr.errVal == nil {
-		if w.sel.args != nil {
-			if w.sel.rows != nil {
-				if w.sel.rows.Next() {
-					w.sharePtr.errVal = w.sel.rows.Scan(w.sel.args...)
-					if w.sharePtr.errVal == nil {
-						return true
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		sliceVl := reflect.ValueOf(recs)
+		if sliceVl.Kind() == reflect.Slice {
+			ctx := context.Background()
+			cmdStr := w.dsc.InsertStr()
+			st := w.prepare(ctx, cmdStr)
+			if w.sharePtr.errVal == nil {
+				var args []interface{}
+				var idFnc func(int64)
+				var err error
+				for j := 0; j < sliceVl.Len() && err == nil; j++ {
+					vl := sliceVl.Index(j)
+					if vl.Kind() != reflect.Ptr && vl.Kind() != reflect.Interface {
+						vl = vl.Addr()
+					}
+					args, idFnc, err = w.dsc.InsertArg(vl.Interface())
+					if err == nil {
+						err = w.insertExec(ctx, st, cmdStr, args, idFnc, false)
+					}
+					if err != nil {
+						w.sharePtr.errVal = &BatchError{Index: j, Err: err}
 					}
-				} else if w.sharePtr.errVal == nil {
-					w.sharePtr.errVal = w.sel.rows.Err()
-					w.sel.args = nil
-					w.sel.rows = nil
 				}
+				st.Close()
 			}
+		} else {
+			w.sharePtr.errVal = errors.New("value passed into batch insert must be a slice of records")
 		}
 	}
-	return false
+}
+
+// UpsertBatch inserts the records in the slice recs or, for those whose
+// values in the conflictCols columns match an existing record, updates all
+// other columns of that record. The records are submitted in multi-row
+// commands of up to 999 parameters each; see DscType.UpsertMultiStr(). The
+// columns in conflictCols must be those of a unique index or composite key of
+// the receiver's descriptor. The commands are executed within the active
+// transaction or, if there is none, within a transaction of their own, so
+// that either all records or none are stored. The identifier fields of the
+// records are not set. If a command fails, a *ChunkError identifying its
+// records is retained.
+func (w *WrapType) UpsertBatch(recs interface{}, conflictCols []string) {
+	defer w.annotate("insert into", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil && !w.dsc.uniqueCols(conflictCols) {
+		w.sharePtr.errVal = fmt.Errorf("conflict columns (%s) are not those of a unique index",
+			strings.Join(conflictCols, ", "))
+	}
+	if w.sharePtr.errVal == nil {
+		sliceVl := reflect.ValueOf(recs)
+		if sliceVl.Kind() == reflect.Slice {
+			var argList [][]interface{}
+			for j := 0; j < sliceVl.Len() && w.sharePtr.errVal == nil; j++ {
+				var args []interface{}
+				var err error
+				args, _, err = w.dsc.UpsertArg(sliceVl.Index(j).Interface(), conflictCols, nil)
+				if err == nil {
+					argList = append(argList, args)
+				} else {
+					w.sharePtr.errVal = &BatchError{Index: j, Err: err}
+				}
+			}
+			if w.sharePtr.errVal == nil && len(argList) > 0 {
+				own := w.sharePtr.tx == nil
+				if own {
+					w.TransactionBegin()
+				}
+				rowMax := insertParamMax / len(w.dsc.insert.sfList)
+				for pos := 0; pos < len(argList) && w.sharePtr.errVal == nil; {
+					chunk := argList[pos:]
+					if len(chunk) > rowMax {
+						chunk = chunk[:rowMax]
+					}
+					var args []interface{}
+					for _, list := range chunk {
+						args = append(args, list...)
+					}
+					w.exec(context.Background(), w.dsc.UpsertMultiStr(len(chunk), conflictCols, nil), args...)
+					if w.sharePtr.errVal != nil {
+						w.sharePtr.errVal = &ChunkError{First: pos, Count: len(chunk), Err: w.sharePtr.errVal}
+					}
+					pos += len(chunk)
+				}
+				if own {
+					w.TransactionEnd()
+				}
+			}
+		} else {
+			w.sharePtr.errVal = errors.New("value passed into batch upsert must be a slice of records")
+		}
+	}
+}
+
+// SetBufferSize sets the number of records that BufferInsert() accumulates
+// before automatically calling Flush(). A value less than one restores the
+// default size of 256 records.
+func (w *WrapType) SetBufferSize(size int) {
+	w.buffer.size = size
+}
+
+// BufferInsert appends the record pointed to by recPtr to an internal buffer
+// rather than adding it to the database immediately. The field values of the
+// record are captured when this method is called, so the same record variable
+// can be reused for subsequent calls. When the number of buffered records
+// reaches the size set with SetBufferSize(), Flush() is called automatically.
+//
+// Records are written to the database in the order in which they were
+// buffered. If the record structure contains an ID field tagged with
+// db_primary and recPtr is a pointer, this field is assigned an identifier
+// only when the buffer is flushed. Regardless of whether the flush succeeds,
+// the identifier is not available before then.
+func (w *WrapType) BufferInsert(recPtr interface{}) {
+	defer w.annotate("insert into", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		var buf bufferType
+		buf.args, buf.setID, w.sharePtr.errVal = w.dsc.InsertArg(recPtr)
+		if w.sharePtr.errVal == nil {
+			w.buffer.list = append(w.buffer.list, buf)
+			size := w.buffer.size
+			if size < 1 {
+				size = bufferSizeDefault
+			}
+			if len(w.buffer.list) >= size {
+				w.Flush()
+			}
+		}
+	}
+}
+
+// Flush writes the records accumulated by BufferInsert() to the database and
+// clears the buffer. The records are inserted with as few multi-row
+// statements as the database's parameter limit allows. If no transaction is
+// active, the statements are executed within one that is begun and ended by
+// this method; otherwise they become part of the active transaction.
+//
+// If an error has already occurred, or occurs during the flush, the buffer is
+// discarded and the error is retained. In the case that this method began the
+// transaction, none of the buffered records are stored. The ID fields of
+// records are set only if the flush succeeds.
+func (w *WrapType) Flush() {
+	defer w.annotate("insert into", w.sharePtr.errVal == nil)
+	list := w.buffer.list
+	w.buffer.list = nil
+	if w.sharePtr.errVal == nil && len(list) > 0 {
+		own := w.sharePtr.tx == nil
+		if own {
+			w.TransactionBegin()
+		}
+		rowMax := insertParamMax / len(w.dsc.insert.sfList)
+		idList := make([]int64, 0, len(list))
+		for pos := 0; pos < len(list) && w.sharePtr.errVal == nil; {
+			chunk := list[pos:]
+			if len(chunk) > rowMax {
+				chunk = chunk[:rowMax]
+			}
+			pos += len(chunk)
+			var args []interface{}
+			for _, buf := range chunk {
+				args = append(args, buf.args...)
+			}
+			cmdStr := w.dsc.InsertMultiStr(len(chunk))
+			if len(w.dsc.returningStr()) > 0 {
+				rows := w.query(context.Background(), cmdStr, args...)
+				if w.sharePtr.errVal == nil {
+					var id int64
+					for w.sharePtr.errVal == nil && rows.Next() {
+						w.sharePtr.errVal = rows.Scan(&id)
+						idList = append(idList, id)
+					}
+					if w.sharePtr.errVal == nil {
+						w.sharePtr.errVal = rows.Err()
+					}
+					rows.Close()
+				}
+			} else {
+				w.exec(context.Background(), cmdStr, args...)
+				if w.sharePtr.errVal == nil {
+					var id int64
+					id, w.sharePtr.errVal = w.res.LastInsertId()
+					// Warning: SQLite3ism; the rows of a single insertion are
+					// assigned consecutive identifiers
+					for j := range chunk {
+						idList = append(idList, id-int64(len(chunk)-1-j))
+					}
+				}
+			}
+		}
+		if w.sharePtr.errVal == nil && len(idList) != len(list) {
+			w.sharePtr.errVal = fmt.Errorf("insertion of %d records returned %d identifiers",
+				len(list), len(idList))
+		}
+		if own {
+			w.TransactionEnd()
+		}
+		if w.sharePtr.errVal == nil {
+			for j, buf := range list {
+				if buf.setID != nil {
+					buf.setID(idList[j])
+				}
+			}
+		}
+	}
+}
+
+// Update stores the passed-in value to the database. rec must be a properly
+// tagged structure variable or a pointer to one. The structure must be one
+// that has an ID field tagged with db_primary. Furthermore, this field must
+// hold a valid ID, typically the same value the record had when it was
+// retrieved with a call to Select(). fldNames is a list of names of the
+// particular tagged fields to update. If the first name is "*", or the list is
+// entirely missing, all tagged fields are stored.
+func (w *WrapType) Update(rec interface{}, fldNames ...string) {
+	w.UpdateContext(context.Background(), rec, fldNames...)
+}
+
+// UpdateContext is like Update() but uses ctx for the database operations.
+func (w *WrapType) UpdateContext(ctx context.Context, rec interface{}, fldNames ...string) {
+	defer w.annotate("update", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		var args []interface{}
+		args, w.sharePtr.errVal = w.dsc.UpdateArg(rec, fldNames...)
+		if w.sharePtr.errVal == nil {
+			w.execCached(ctx, w.dsc.UpdateStr(fldNames...), args...)
+		}
+	}
+}
+
+// UpdateAll updates, as in Update(), each record in the slice recs. The
+// elements of recs may be records or pointers to records. The UPDATE command
+// is prepared once and executed for each element within the active
+// transaction, if there is one. Wrapping the call in a transaction avoids
+// committing each update separately. The total number of affected rows is
+// returned. If an element cannot be updated, a *BatchError identifying it is
+// retained and the remaining elements are not processed.
+func (w *WrapType) UpdateAll(recs interface{}, fldNames ...string) (count int64) {
+	defer w.annotate("update", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		sliceVl := reflect.ValueOf(recs)
+		if sliceVl.Kind() != reflect.Slice {
+			w.sharePtr.errVal = fmt.Errorf("passed-in value must be a slice of %s",
+				w.dsc.recTp.String())
+			return
+		}
+		cmdStr := w.dsc.UpdateStr(fldNames...)
+		st := w.prepareCached(context.Background(), cmdStr)
+		for j := 0; j < sliceVl.Len() && w.sharePtr.errVal == nil; j++ {
+			var args []interface{}
+			var err error
+			args, err = w.dsc.UpdateArg(sliceVl.Index(j).Interface(), fldNames...)
+			if err == nil {
+				start := w.traceStart()
+				w.res, err = st.Exec(args...)
+				w.trace(start, cmdStr, args, err)
+				if err == nil {
+					w.accumulate()
+					rowCount, countErr := w.res.RowsAffected()
+					if countErr == nil {
+						count += rowCount
+					}
+				}
+			}
+			if err != nil {
+				w.sharePtr.errVal = &BatchError{Index: j, Err: err}
+			}
+		}
+	}
+	return
+}
+
+// UpdateWhere sets the columns named by the keys of setCols to the
+// corresponding values in all records that satisfy tailStr, for example
+// "WHERE processed = ?". For each question mark in tailStr, there must be an
+// appropriate parameter in the args list. If tailStr is empty and args not
+// passed, all records in the table are updated. The number of updated records
+// is available from RowsAffected(). See DscType.UpdateWhereArg().
+func (w *WrapType) UpdateWhere(setCols map[string]interface{}, tailStr string, args ...interface{}) {
+	defer w.annotate("update", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		var cmdStr string
+		var argList []interface{}
+		cmdStr, argList, w.sharePtr.errVal = w.dsc.UpdateWhereArg(setCols, tailStr, args...)
+		if w.sharePtr.errVal == nil {
+			w.execCached(context.Background(), cmdStr, argList...)
+		}
+	}
+}
+
+// CompareAndSwap sets the field associated with the column nameStr of the
+// stored record identified by rec to newVal, but only if the column currently
+// holds oldVal. The check and the change are made in a single command, so
+// concurrent swaps of the same record cannot both succeed. True is returned if
+// the record was changed, in which case the field of the record is also set
+// if rec is a pointer. False is returned if the column held another value, if
+// no record is identified by rec, or if an error occurs. The record is
+// identified as in Update(). See DscType.CompareAndSwapArg().
+func (w *WrapType) CompareAndSwap(rec interface{}, nameStr string, oldVal, newVal interface{}) (swapped bool) {
+	defer w.annotate("update", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		var args []interface{}
+		args, w.sharePtr.errVal = w.dsc.CompareAndSwapArg(rec, nameStr, oldVal, newVal)
+		if w.sharePtr.errVal == nil {
+			w.execCached(context.Background(), w.dsc.CompareAndSwapStr(nameStr), args...)
+			if w.sharePtr.errVal == nil {
+				var count int64
+				count, w.sharePtr.errVal = w.res.RowsAffected()
+				swapped = w.sharePtr.errVal == nil && count > 0
+			}
+		}
+		if swapped {
+			vl := reflect.ValueOf(rec)
+			if vl.Kind() == reflect.Ptr {
+				fldVl := vl.Elem().FieldByIndex(w.dsc.nameMap[nameStr].Index)
+				fldVl.Set(reflect.ValueOf(newVal).Convert(fldVl.Type()))
+			}
+		}
+	}
+	return
+}
+
+// Create adds a new table and indexes of the type associated with the receiver.
+func (w *WrapType) Create() {
+	w.create(w.dsc.CreateStr)
+}
+
+// CreateIfNotExists is like Create() except that no error occurs if the table
+// or indexes already exist. This permits a program to prepare its tables each
+// time it starts. See DscType.CreateStrIfNotExists().
+func (w *WrapType) CreateIfNotExists() {
+	w.create(w.dsc.CreateStrIfNotExists)
+}
+
+// create executes the commands returned by createStr.
+func (w *WrapType) create(createStr func() (string, []string)) {
+	defer w.annotate("create", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		cmdStr, idxList := createStr()
+		w.exec(context.Background(), cmdStr)
+		for _, cmdStr = range idxList {
+			if w.sharePtr.errVal == nil {
+				w.exec(context.Background(), cmdStr)
+			}
+		}
+	}
+}
+
+// Drop removes the table and indexes of the type associated with the
+// receiver. If ifExists is true, no error occurs if the table or its indexes
+// do not exist.
+func (w *WrapType) Drop(ifExists bool) {
+	defer w.annotate("drop", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		cmdStr, idxList := w.dsc.DropStr(ifExists)
+		for _, idxStr := range idxList {
+			if w.sharePtr.errVal == nil {
+				w.exec(context.Background(), idxStr)
+			}
+		}
+		if w.sharePtr.errVal == nil {
+			w.exec(context.Background(), cmdStr)
+		}
+	}
+}
+
+// Delete removes database rows that satisfy the WHERE clause in tailStr. For
+// each question mark in tailStr, there must be an appropriate parameter in the
+// args list. If tailStr is empty and args not passed, all records in the table
+// will be deleted. If the record structure has a field tagged
+// "db_softdelete", the rows are instead marked as deleted; see
+// DscType.SoftDeleteArg() and DeleteHard(). As with insertions and updates,
+// the deletion takes place within the active transaction if there is one.
+func (w *WrapType) Delete(tailStr string, args ...interface{}) {
+	w.DeleteContext(context.Background(), tailStr, args...)
+}
+
+// DeleteContext is like Delete() but uses ctx for the database operation.
+func (w *WrapType) DeleteContext(ctx context.Context, tailStr string, args ...interface{}) {
+	defer w.annotate("delete from", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		cmdStr, argList := w.dsc.SoftDeleteArg(tailStr, args...)
+		w.execCached(ctx, cmdStr, argList...)
+	}
+}
+
+// DeleteRec removes the stored record identified by rec, a properly tagged
+// structure variable or a pointer to one. As with Update(), the structure
+// must have an ID field tagged with db_primary or a composite key, and the
+// record is identified by its value. If the record structure has a field
+// tagged "db_softdelete", the record is instead marked as deleted. The number
+// of affected rows, zero if no record matched, is available from
+// RowsAffected().
+func (w *WrapType) DeleteRec(rec interface{}) {
+	defer w.annotate("delete from", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		var cmdStr string
+		var args []interface{}
+		cmdStr, args, w.sharePtr.errVal = w.dsc.DeleteRecArg(rec)
+		if w.sharePtr.errVal == nil {
+			w.execCached(context.Background(), cmdStr, args...)
+		}
+	}
+}
+
+// DeleteHard is like Delete() except that the rows are removed even if the
+// record structure has a field tagged "db_softdelete".
+func (w *WrapType) DeleteHard(tailStr string, args ...interface{}) {
+	defer w.annotate("delete from", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		w.execCached(context.Background(), w.dsc.DeleteStr(tailStr), args...)
+	}
+}
+
+// nullable returns true if a NULL value can be scanned into the target
+// pointed to by ptr.
+func nullable(ptr interface{}) bool {
+	if _, ok := ptr.(sql.Scanner); ok {
+		return true
+	}
+	switch reflect.TypeOf(ptr).Elem().Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+// scanRow copies the current row of rows into the targets in fldList. If the
+// scan fails because a NULL value was read into a target that cannot hold it,
+// the returned error identifies the column.
+func scanRow(rows *sql.Rows, fldList []interface{}) (err error) {
+	err = rows.Scan(fldList...)
+	if err != nil {
+		// Scan the row again to look for the offending NULL value
+		rawList := make([]interface{}, len(fldList))
+		ptrList := make([]interface{}, len(fldList))
+		for j := range rawList {
+			ptrList[j] = &rawList[j]
+		}
+		colList, colErr := rows.Columns()
+		if colErr == nil && len(colList) == len(fldList) && rows.Scan(ptrList...) == nil {
+			for j, raw := range rawList {
+				if raw == nil && !nullable(fldList[j]) {
+					return fmt.Errorf("column \"%s\" is NULL but its field of type %s "+
+						"cannot hold NULL; use a pointer or sql.Null type instead", colList[j],
+						reflect.TypeOf(fldList[j]).Elem().String())
+				}
+			}
+		}
+	}
+	return
+}
+
+// QueryRow submits a SELECT command to the database. recPtr must be a pointer
+// to a properly tagged structure variable. tail contains the portion of the
+// SELECT command that filters and orders the results. tail should be
+// constructed so that at most one row is returned. This may involve including
+// a LIMIT clause in it. For each question mark in tail, there must be an
+// appropriate parameter in the args list. tail may also be a Tailer such as
+// TailType, in which case its parameters precede args. This command is
+// self-contained; it is an error to use it in conjunction with Next().
+func (w *WrapType) QueryRow(recPtr interface{}, tail interface{}, args ...interface{}) {
+	w.QueryRowContext(context.Background(), recPtr, tail, args...)
+}
+
+// QueryRowContext is like QueryRow() but uses ctx for the database
+// operation.
+func (w *WrapType) QueryRowContext(ctx context.Context, recPtr interface{}, tail interface{}, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	w.CloseRows()
+	if w.sharePtr.errVal == nil {
+		var fldList []interface{}
+		var tailStr string
+		tailStr, args, w.sharePtr.errVal = tailArgs(tail, args)
+		if w.sharePtr.errVal == nil {
+			fldList, w.sharePtr.errVal = w.dsc.SelectArg(recPtr)
+		}
+		if w.sharePtr.errVal == nil {
+			rows := w.queryRecords(ctx, w.dsc.SelectStr(tailStr), len(fldList), args...)
+			if w.sharePtr.errVal == nil {
+				if rows.Next() {
+					w.sharePtr.errVal = scanRow(rows, fldList)
+				} else {
+					w.sharePtr.errVal = rows.Err()
+					if w.sharePtr.errVal == nil {
+						w.sharePtr.errVal = sql.ErrNoRows
+					}
+				}
+				rows.Close()
+			}
+		}
+	}
+}
+
+// Get is like QueryRow() except that the absence of a matching record is not
+// an error. It returns true if a record was retrieved into the structure
+// pointed to by recPtr and false otherwise. In particular, false is returned
+// without retaining an error if no record satisfies tailStr, which simplifies
+// the lookup of a record that is to be created if it does not exist. Other
+// errors are retained as usual.
+func (w *WrapType) Get(recPtr interface{}, tailStr string, args ...interface{}) (found bool) {
+	if w.sharePtr.errVal == nil {
+		w.QueryRow(recPtr, tailStr, args...)
+		if errors.Is(w.sharePtr.errVal, sql.ErrNoRows) {
+			w.sharePtr.errVal = nil
+		} else {
+			found = w.sharePtr.errVal == nil
+		}
+	}
+	return
+}
+
+// Reload reads again from the database the record pointed to by recPtr,
+// overwriting all of its fields. The row is identified by the record's primary
+// key, so recPtr usually points to a record that was earlier inserted or
+// retrieved. An error occurs if the record structure has no primary key. If
+// the row no longer exists, sql.ErrNoRows is retained.
+func (w *WrapType) Reload(recPtr interface{}) {
+	if w.sharePtr.errVal == nil {
+		if !w.dsc.idPresent && len(w.dsc.key.nameList) == 0 {
+			w.sharePtr.errVal = errors.New("reloading a record requires structure with primary ID")
+		} else {
+			_, w.sharePtr.errVal = w.dsc.SelectArg(recPtr)
+		}
+		if w.sharePtr.errVal == nil {
+			args := w.dsc.keyArg(reflect.ValueOf(recPtr).Elem())
+			w.QueryRow(recPtr, "WHERE "+w.dsc.keyWhereStr(1), args...)
+		}
+	}
+}
+
+// Count returns the number of records in the table associated with the
+// receiver that satisfy tailStr. For each question mark in tailStr, there must
+// be an appropriate parameter in the args list. If tailStr is empty and args
+// not passed, all records in the table are counted. Zero is returned if an
+// error occurs.
+func (w *WrapType) Count(tailStr string, args ...interface{}) (count int64) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.queryRow(context.Background(), w.dsc.CountStr(tailStr), args...).Scan(&count)
+	}
+	return
+}
+
+// Exists returns true if any record in the table associated with the receiver
+// satisfies tailStr. For each question mark in tailStr, there must be an
+// appropriate parameter in the args list. Unlike QueryRow(), no record is
+// retrieved. False is returned if an error occurs.
+func (w *WrapType) Exists(tailStr string, args ...interface{}) (found bool) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.queryRow(context.Background(), w.dsc.ExistsStr(tailStr), args...).Scan(&found)
+	}
+	return
+}
+
+// ScalarGrouped stores in the variable pointed to by dest the single value
+// of the command returned by DscType.ScalarGroupedStr() with the same
+// selectExpr, groupByStr, havingStr and tailStr. For example, with selectExpr
+// "COUNT(*)", groupByStr "num" and havingStr "COUNT(*) > ?", it counts the
+// values of num that occur in more than the number of records given by the
+// parameter. args holds the parameters of tailStr followed by those of
+// havingStr. When the receiver's dialect uses question marks as placeholders,
+// an error occurs if their number does not match the number of parameters.
+// If the command returns no row, sql.ErrNoRows is retained.
+func (w *WrapType) ScalarGrouped(dest interface{}, selectExpr, groupByStr, havingStr, tailStr string,
+	args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		cmdStr := w.dsc.ScalarGroupedStr(selectExpr, groupByStr, havingStr, tailStr)
+		if w.dsc.dialect.PlaceholderMark(1) == "?" {
+			count := strings.Count(selectExpr+groupByStr+havingStr+tailStr, "?")
+			if count != len(args) {
+				w.sharePtr.errVal = fmt.Errorf("grouped query has %d placeholders but %d parameters",
+					count, len(args))
+			}
+		}
+		if w.sharePtr.errVal == nil {
+			w.sharePtr.errVal = w.queryRow(context.Background(), cmdStr, args...).Scan(dest)
+		}
+	}
+}
+
+// Query submits a SELECT command to the database. recPtr must be a pointer to
+// a properly tagged structure variable. tail contains the portion of the
+// SELECT command that filters and orders the results. For each question mark
+// in tail, there must be an appropriate parameter in the args list. If
+// tail is empty and args not passed, all records in the table will be
+// selected. tail may also be a Tailer such as TailType, in which case its
+// parameters precede args. This command works in conjunction with Next().
+func (w *WrapType) Query(recPtr interface{}, tail interface{}, args ...interface{}) {
+	w.QueryContext(context.Background(), recPtr, tail, args...)
+}
+
+// QueryContext is like Query() but uses ctx for the database operation. If
+// ctx is canceled before all rows have been retrieved with Next(), the result
+// set is closed and the cancellation error is retained.
+func (w *WrapType) QueryContext(ctx context.Context, recPtr interface{}, tail interface{}, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	w.CloseRows()
+	if w.sharePtr.errVal == nil {
+		var tailStr string
+		tailStr, args, w.sharePtr.errVal = tailArgs(tail, args)
+		if w.sharePtr.errVal == nil {
+			w.sel.args, w.sharePtr.errVal = w.dsc.SelectArg(recPtr)
+		}
+		if w.sharePtr.errVal == nil {
+			w.sel.rows = w.queryRecords(ctx, w.dsc.SelectStr(tailStr), len(w.sel.args), args...)
+			w.sel.ctx = ctx
+		}
+	}
+}
+
+// ForEach is like Query() except that, rather than being retrieved with
+// Next(), each resulting row is copied to the record pointed to by recPtr and
+// then passed to fn by way of that record. If fn returns an error, no further
+// rows are retrieved and the error is retained. The result set is closed when
+// ForEach returns, even if it returns early.
+func (w *WrapType) ForEach(recPtr interface{}, tailStr string, fn func() error, args ...interface{}) {
+	w.Query(recPtr, tailStr, args...)
+	for w.Next() {
+		w.SetError(fn())
+	}
+	w.CloseRows()
+}
+
+// QueryReuse submits a SELECT command to the database and stores the
+// resulting rows in the slice pointed to by slicePtr. The slice elements must
+// be of the properly tagged structure type associated with the receiver.
+// tailStr and args are used as in Query(). The length of the slice is set to
+// the number of rows read. Elements within the slice's existing capacity are
+// reused and the slice is grown only when more rows are retrieved than it can
+// hold. This reduces allocations when the same slice is used for repeated
+// queries.
+//
+// Because the backing storage is reused, the previous contents of the slice
+// are overwritten. Any other slice that shares this storage will observe the
+// new records. Fields that are not tagged for the database retain whatever
+// value the reused element previously held.
+func (w *WrapType) QueryReuse(slicePtr interface{}, tailStr string, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		var sliceVl reflect.Value
+		sliceVl, w.sharePtr.errVal = w.dsc.sliceValue(slicePtr)
+		if w.sharePtr.errVal == nil {
+			rows := w.queryRecords(context.Background(), w.dsc.SelectStr(tailStr),
+				len(w.dsc.sel.sfList), args...)
+			if w.sharePtr.errVal == nil {
+				var count int
+				fldList := make([]interface{}, len(w.dsc.sel.sfList))
+				for w.sharePtr.errVal == nil && rows.Next() {
+					if count < sliceVl.Cap() {
+						sliceVl.SetLen(count + 1)
+					} else {
+						sliceVl.Set(reflect.Append(sliceVl, reflect.Zero(w.dsc.recTp)))
+					}
+					w.dsc.selectFill(sliceVl.Index(count), fldList)
+					w.sharePtr.errVal = scanRow(rows, fldList)
+					if w.sharePtr.errVal == nil {
+						count++
+					}
+				}
+				if w.sharePtr.errVal == nil {
+					w.sharePtr.errVal = rows.Err()
+				}
+				rows.Close()
+				sliceVl.SetLen(count)
+			}
+		}
+	}
+}
+
+// QueryPage is like Query() except that only the records of page number page,
+// each page holding pageSize records, are retrieved. whereStr is the portion
+// of the SELECT command that filters and orders the results, for example
+// "WHERE num > ? ORDER BY num", and args are its parameters; the LIMIT and
+// OFFSET clauses and their parameters are appended to them. Pages are
+// numbered from one and a page less than one is treated as the first page. An
+// error occurs if pageSize is less than one. whereStr should include an ORDER
+// BY clause that orders the records uniquely, since otherwise the database
+// may return records in a different order from one query to the next, so that
+// pages overlap or skip records. Like Query(), this method works in
+// conjunction with Next().
+func (w *WrapType) QueryPage(recPtr interface{}, whereStr string, page, pageSize int, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		if pageSize < 1 {
+			w.sharePtr.errVal = fmt.Errorf("page size %d is less than one", pageSize)
+		} else {
+			if page < 1 {
+				page = 1
+			}
+			n := len(args)
+			tailStr := whereStr + " " + w.dsc.dialect.LimitClause(
+				w.dsc.dialect.PlaceholderMark(n+1), w.dsc.dialect.PlaceholderMark(n+2))
+			args = append(args[:n:n], pageSize, (page-1)*pageSize)
+			w.Query(recPtr, strings.TrimSpace(tailStr), args...)
+		}
+	}
+}
+
+// QueryExtra is like Query() except that the complete SELECT command cmdStr,
+// for example one that reads a view or computes columns that the record
+// structure does not have, is submitted with args. Columns of the result are
+// bound to the fields of the record pointed to by recPtr by name, and the
+// extra columns are passed to extra as described in DscType.BindColumns().
+// extra is called once, before the first call to Next(). Each call to Next()
+// then stores the values of the extra columns of the row in the targets. If
+// extra is nil, the values of extra columns are discarded.
+func (w *WrapType) QueryExtra(recPtr interface{}, extra func(extra []interface{}),
+	cmdStr string, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	w.CloseRows()
+	if w.sharePtr.errVal == nil {
+		_, w.sharePtr.errVal = w.dsc.SelectArg(recPtr)
+		if w.sharePtr.errVal == nil {
+			rows := w.query(context.Background(), cmdStr, args...)
+			if w.sharePtr.errVal == nil {
+				var cols []string
+				cols, w.sharePtr.errVal = rows.Columns()
+				if w.sharePtr.errVal == nil {
+					w.sel.args, w.sharePtr.errVal = w.dsc.BindColumns(recPtr, cols, extra)
+				}
+				if w.sharePtr.errVal == nil {
+					w.sel.rows = rows
+				} else {
+					rows.Close()
+				}
+			}
+		}
+	}
+}
+
+// QueryAll submits a SELECT command to the database and appends each
+// resulting row, as a new record, to the slice pointed to by slicePtr. The
+// slice elements must be of the properly tagged structure type associated
+// with the receiver. tailStr and args are used as in Query(). The slice is
+// left unchanged if no rows are retrieved or if an error occurs. Unlike
+// QueryReuse(), the existing contents of the slice are kept.
+func (w *WrapType) QueryAll(slicePtr interface{}, tailStr string, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		var sliceVl reflect.Value
+		sliceVl, w.sharePtr.errVal = w.dsc.sliceValue(slicePtr)
+		if w.sharePtr.errVal == nil {
+			rows := w.queryRecords(context.Background(), w.dsc.SelectStr(tailStr),
+				len(w.dsc.sel.sfList), args...)
+			if w.sharePtr.errVal == nil {
+				listVl := sliceVl
+				recVl := reflect.New(w.dsc.recTp).Elem()
+				fldList := make([]interface{}, len(w.dsc.sel.sfList))
+				w.dsc.selectFill(recVl, fldList)
+				for w.sharePtr.errVal == nil && rows.Next() {
+					recVl.Set(reflect.Zero(w.dsc.recTp))
+					w.sharePtr.errVal = scanRow(rows, fldList)
+					if w.sharePtr.errVal == nil {
+						listVl = reflect.Append(listVl, recVl)
+					}
+				}
+				if w.sharePtr.errVal == nil {
+					w.sharePtr.errVal = rows.Err()
+				}
+				rows.Close()
+				if w.sharePtr.errVal == nil {
+					sliceVl.Set(listVl)
+				}
+			}
+		}
+	}
+}
+
+// QueryMap submits a SELECT command to the database and stores the resulting
+// records in the map pointed to by mapPtr, keyed by their primary key. The
+// map's elements must be of the properly tagged structure type associated
+// with the receiver and its keys must be of the same kind as the field tagged
+// with "db_primary", for example map[int64]recType. The map is allocated if
+// it is nil; records already present in it are retained unless a retrieved
+// record has the same key. An error occurs if the record structure does not
+// have a primary key made of a single field. tailStr and args are used as in
+// Query().
+func (w *WrapType) QueryMap(mapPtr interface{}, tailStr string, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		var mapVl reflect.Value
+		var keySf reflect.StructField
+		mapVl, keySf, w.sharePtr.errVal = w.dsc.mapValue(mapPtr)
+		if w.sharePtr.errVal == nil {
+			rows := w.queryRecords(context.Background(), w.dsc.SelectStr(tailStr),
+				len(w.dsc.sel.sfList), args...)
+			if w.sharePtr.errVal == nil {
+				keyTp := mapVl.Type().Key()
+				recVl := reflect.New(w.dsc.recTp).Elem()
+				fldList := make([]interface{}, len(w.dsc.sel.sfList))
+				w.dsc.selectFill(recVl, fldList)
+				for w.sharePtr.errVal == nil && rows.Next() {
+					recVl.Set(reflect.Zero(w.dsc.recTp))
+					w.sharePtr.errVal = scanRow(rows, fldList)
+					if w.sharePtr.errVal == nil {
+						keyVl := recVl.FieldByIndex(keySf.Index).Convert(keyTp)
+						mapVl.SetMapIndex(keyVl, recVl)
+					}
+				}
+				if w.sharePtr.errVal == nil {
+					w.sharePtr.errVal = rows.Err()
+				}
+				rows.Close()
+			}
+		}
+	}
+}
+
+// QueryWithDeleted is like Query() except that soft-deleted records are
+// included.
+func (w *WrapType) QueryWithDeleted(recPtr interface{}, tailStr string, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	w.CloseRows()
+	if w.sharePtr.errVal == nil {
+		w.sel.args, w.sharePtr.errVal = w.dsc.SelectArg(recPtr)
+		if w.sharePtr.errVal == nil {
+			w.sel.rows = w.queryRecords(context.Background(), w.dsc.SelectWithDeletedStr(tailStr),
+				len(w.sel.args), args...)
+		}
+	}
+}
+
+// QueryCols is like Query() except that only the columns named in cols are
+// retrieved into the record pointed to by recPtr. The other fields of the
+// record are left unchanged by Next(). See DscType.SelectColsArg().
+func (w *WrapType) QueryCols(recPtr interface{}, cols []string, tailStr string, args ...interface{}) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	w.CloseRows()
+	if w.sharePtr.errVal == nil {
+		w.sel.args, w.sharePtr.errVal = w.dsc.SelectColsArg(recPtr, cols)
+		if w.sharePtr.errVal == nil {
+			w.sel.rows = w.queryRecords(context.Background(), w.dsc.SelectColsStr(cols, tailStr),
+				len(w.sel.args), args...)
+		}
+	}
+}
+
+// Search is like Query() except that the records retrieved are those in which
+// any of the columns named in cols contains termStr, ordered as specified by
+// opt. Like Query(), it works in conjunction with Next(). See
+// DscType.SearchStr().
+func (w *WrapType) Search(recPtr interface{}, cols []string, termStr string, opt SearchOpt) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	w.CloseRows()
+	if w.sharePtr.errVal == nil {
+		var cmdStr string
+		var args []interface{}
+		cmdStr, args, w.sharePtr.errVal = w.dsc.SearchStr(cols, termStr, opt)
+		if w.sharePtr.errVal == nil {
+			w.sel.args, w.sharePtr.errVal = w.dsc.SelectArg(recPtr)
+			if w.sharePtr.errVal == nil {
+				w.sel.rows = w.queryRecords(context.Background(), cmdStr, len(w.sel.args), args...)
+			}
+		}
+	}
+}
+
+// maintain executes the maintenance command cmdStr. Such commands cannot be
+// run within a transaction.
+func (w *WrapType) maintain(cmdStr string) {
+	if w.sharePtr.errVal == nil {
+		if w.sharePtr.tx == nil {
+			w.exec(context.Background(), cmdStr)
+		} else {
+			w.sharePtr.errVal = fmt.Errorf("%s cannot be run within a transaction",
+				strings.TrimSuffix(cmdStr, ";"))
+		}
+	}
+}
+
+// Vacuum rebuilds the database in order to reclaim unused space. On
+// PostgreSQL, the space is made available for reuse rather than returned to
+// the operating system. An error occurs if a transaction is active.
+func (w *WrapType) Vacuum() {
+	w.maintain("VACUUM;")
+}
+
+// Analyze gathers the statistics that the query planner uses to choose
+// efficient ways of executing commands. An error occurs if a transaction is
+// active.
+func (w *WrapType) Analyze() {
+	w.maintain("ANALYZE;")
+}
+
+// Optimize performs the routine maintenance recommended for the dialect of
+// the receiver's descriptor: PRAGMA optimize on SQLite and VACUUM ANALYZE on
+// PostgreSQL. An error occurs if a transaction is active.
+func (w *WrapType) Optimize() {
+	w.maintain(w.dsc.dialect.OptimizeStr())
+}
+
+// EnableForeignKeys turns on the enforcement of the foreign key constraints
+// declared with "db_fk" tags, for databases such as SQLite that do not enforce
+// them by default. SQLite applies this setting to a single connection, so
+// the handle should be limited to one open connection (see
+// sql.DB.SetMaxOpenConns()) or the setting should be made in the data source
+// name. An error occurs if a transaction is active.
+func (w *WrapType) EnableForeignKeys() {
+	cmdStr := w.dsc.dialect.ForeignKeysStr()
+	if len(cmdStr) > 0 {
+		w.maintain(cmdStr)
+	}
+}
+
+// IndexDrift compares the indexes that the receiver's descriptor declares
+// with those of the table in the database. missing contains the names of the
+// declared indexes that the table lacks and extra contains the names of the
+// table's indexes that are not declared; both are sorted. Indexes that the
+// database creates automatically are not considered. The returned error is the
+// same as that returned by Err().
+func (w *WrapType) IndexDrift() (missing, extra []string, err error) {
+	missing, extra = w.indexDrift()
+	err = w.sharePtr.errVal
+	return
+}
+
+// indexDrift implements IndexDrift().
+func (w *WrapType) indexDrift() (missing, extra []string) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		liveMap := make(map[string]bool)
+		rows := w.query(context.Background(), w.dsc.dialect.IndexListStr(), w.dsc.tblStr)
+		if w.sharePtr.errVal == nil {
+			var nameStr string
+			for w.sharePtr.errVal == nil && rows.Next() {
+				w.sharePtr.errVal = rows.Scan(&nameStr)
+				liveMap[nameStr] = true
+			}
+			if w.sharePtr.errVal == nil {
+				w.sharePtr.errVal = rows.Err()
+			}
+			rows.Close()
+		}
+		if w.sharePtr.errVal == nil {
+			for _, nameStr := range w.dsc.Indexes() {
+				if liveMap[nameStr] {
+					delete(liveMap, nameStr)
+				} else {
+					missing = append(missing, nameStr)
+				}
+			}
+			for nameStr := range liveMap {
+				extra = append(extra, nameStr)
+			}
+			sort.Strings(extra)
+		}
+	}
+	return
+}
+
+// ColumnDrift compares the columns that the receiver's descriptor declares
+// with those of the table in the database. missing contains the names of the
+// declared columns that the table lacks, in declaration order, and extra
+// contains the names of the table's columns that are not declared, in table
+// order. The implicit primary key column is not considered. The returned error
+// is the same as that returned by Err().
+func (w *WrapType) ColumnDrift() (missing, extra []string, err error) {
+	missing, extra = w.columnDrift()
+	err = w.sharePtr.errVal
+	return
+}
+
+// columnDrift implements ColumnDrift().
+func (w *WrapType) columnDrift() (missing, extra []string) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		liveList, _ := w.liveColumns()
+		if w.sharePtr.errVal == nil {
+			liveMap := make(map[string]bool)
+			for _, nameStr := range liveList {
+				liveMap[nameStr] = true
+			}
+			declMap := make(map[string]bool)
+			if nameStr, ok := w.dsc.PrimaryKey(); ok {
+				declMap[nameStr] = true
+			}
+			for _, nameStr := range w.dsc.Columns() {
+				declMap[nameStr] = true
+				if !liveMap[nameStr] {
+					missing = append(missing, nameStr)
+				}
+			}
+			for _, nameStr := range liveList {
+				if !declMap[nameStr] {
+					extra = append(extra, nameStr)
+				}
+			}
+		}
+	}
+	return
+}
+
+// liveColumns returns the names of the columns of the table in the database,
+// in table order, and their declared types, as retrieved with the query of
+// Dialect.ColumnListStr(). An error is retained if the table does not exist.
+func (w *WrapType) liveColumns() (nameList, typeList []string) {
+	rows := w.query(context.Background(), w.dsc.dialect.ColumnListStr(), w.dsc.tblStr)
+	if w.sharePtr.errVal == nil {
+		var nameStr, typeStr string
+		for w.sharePtr.errVal == nil && rows.Next() {
+			w.sharePtr.errVal = rows.Scan(&nameStr, &typeStr)
+			nameList = append(nameList, nameStr)
+			typeList = append(typeList, typeStr)
+		}
+		if w.sharePtr.errVal == nil {
+			w.sharePtr.errVal = rows.Err()
+		}
+		rows.Close()
+		if w.sharePtr.errVal == nil && len(nameList) == 0 {
+			w.sharePtr.errVal = fmt.Errorf("table %s does not exist in the database", w.dsc.tblStr)
+		}
+	}
+	return
+}
+
+// Migrate brings the table in the database up to date with the receiver's
+// descriptor by adding the columns that the table lacks, as reported by
+// ColumnDrift(). Existing columns are left as they are; none is removed or
+// retyped, and indexes are not affected (see MigrationPlan()). Before any
+// column is added, the type of each existing column is compared with the type
+// that the descriptor declares for it. If one holds text and the other holds
+// numbers, the table cannot be reconciled by adding columns, so no column is
+// added and an error is retained. Note that SQLite cannot add a NOT NULL
+// column that has no default value.
+func (w *WrapType) Migrate() {
+	defer w.annotate("alter", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal == nil {
+		w.sharePtr.errVal = w.dsc.writable()
+	}
+	if w.sharePtr.errVal == nil {
+		liveList, typeList := w.liveColumns()
+		if w.sharePtr.errVal == nil {
+			liveMap := make(map[string]string)
+			for j, nameStr := range liveList {
+				liveMap[nameStr] = typeList[j]
+			}
+			var missing []string
+			for j, nameStr := range w.dsc.insert.nameList {
+				if w.sharePtr.errVal == nil {
+					liveStr, ok := liveMap[nameStr]
+					if ok {
+						declStr := w.dsc.columnType(j)
+						liveFamily, declFamily := typeFamily(liveStr), typeFamily(declStr)
+						if len(liveFamily) > 0 && len(declFamily) > 0 &&
+							(liveFamily == "text") != (declFamily == "text") {
+							w.sharePtr.errVal = fmt.Errorf("column \"%s\" of table %s is declared as %s "+
+								"but has type %s in the database", nameStr, w.dsc.tblStr, declStr,
+								strings.ToLower(liveStr))
+						}
+					} else {
+						missing = append(missing, nameStr)
+					}
+				}
+			}
+			for j := 0; w.sharePtr.errVal == nil && j < len(missing); j++ {
+				var cmdStr string
+				cmdStr, w.sharePtr.errVal = w.dsc.AddColumnStr(missing[j])
+				if w.sharePtr.errVal == nil {
+					w.exec(context.Background(), cmdStr)
+				}
+			}
+		}
+	}
+}
+
+// MigrationPlan returns, without executing them, the commands that would
+// bring the table in the database up to date with the receiver's descriptor:
+// an ALTER TABLE command for each missing column, as reported by
+// ColumnDrift(), followed by a command for each missing index, as reported by
+// IndexDrift(). The plan never removes or alters existing columns or indexes,
+// so extra columns and indexes are left in place. Note that SQLite cannot add
+// a NOT NULL column that has no default value. The returned error is the same
+// as that returned by Err().
+func (w *WrapType) MigrationPlan() (cmdList []string, err error) {
+	cmdList = w.migrationPlan()
+	err = w.sharePtr.errVal
+	return
+}
+
+// migrationPlan implements MigrationPlan().
+func (w *WrapType) migrationPlan() (cmdList []string) {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	missing, _ := w.columnDrift()
+	for j := 0; w.sharePtr.errVal == nil && j < len(missing); j++ {
+		var cmdStr string
+		cmdStr, w.sharePtr.errVal = w.dsc.AddColumnStr(missing[j])
+		cmdList = append(cmdList, cmdStr)
+	}
+	if w.sharePtr.errVal == nil {
+		missing, _ = w.indexDrift()
+		if w.sharePtr.errVal == nil {
+			idxMap := w.dsc.indexStrMap("")
+			for _, nameStr := range missing {
+				cmdList = append(cmdList, idxMap[nameStr])
+			}
+		}
+	}
+	return
+}
+
+// SchemaVersion returns the schema version of the database, which
+// applications can use to keep track of migrations. The version is zero until
+// it is set with SetSchemaVersion(). It belongs to the database as a whole
+// (with SQLite, to the database file) rather than to the receiver's table. The
+// returned error is the same as that returned by Err().
+func (w *WrapType) SchemaVersion() (v int, err error) {
+	cmdList := w.dsc.dialect.SchemaVersionStr()
+	for j, cmdStr := range cmdList {
+		if w.sharePtr.errVal == nil {
+			if j < len(cmdList)-1 {
+				w.exec(context.Background(), cmdStr)
+			} else {
+				w.sharePtr.errVal = w.queryRow(context.Background(), cmdStr).Scan(&v)
+			}
+		}
+	}
+	err = w.sharePtr.errVal
+	return
+}
+
+// SetSchemaVersion sets the schema version of the database to v. See
+// SchemaVersion().
+func (w *WrapType) SetSchemaVersion(v int) {
+	for _, cmdStr := range w.dsc.dialect.SetSchemaVersionStr(v) {
+		if w.sharePtr.errVal == nil {
+			w.exec(context.Background(), cmdStr)
+		}
+	}
+}
+
+// Next retrieves the next row in the result set generated with a call to
+// Query(). Each row in turn is copied to the record variable pointed to the
+// recPtr argument in Query(). This method should be called repeatedly until it
+// returns false. This happens when there are no more rows to retrieve or an
+// error occurs, including one retained by another operation since the last
+// call. In either case, the result set is closed. A caller that stops calling
+// Next() before it returns false should call CloseRows() so that the database
+// connection held by the result set is released; otherwise it is released
+// when the receiver next submits a query.
+func (w *WrapType) Next() bool {
+	defer w.annotate("query", w.sharePtr.errVal == nil)
+	if w.sharePtr.errVal != nil {
+		w.CloseRows()
+	} else {
+		if w.sel.args != nil {
+			if w.sel.rows != nil {
+				if w.sel.ctx != nil && w.sel.ctx.Err() != nil {
+					// The driver may have buffered rows after the cancellation
+					w.sharePtr.errVal = w.sel.ctx.Err()
+					w.CloseRows()
+				} else if w.sel.rows.Next() {
+					w.sharePtr.errVal = scanRow(w.sel.rows, w.sel.args)
+					if w.sharePtr.errVal == nil {
+						return true
+					}
+					// Release the connection held by the abandoned result set
+					w.sel.rows.Close()
+					w.sel.args = nil
+					w.sel.rows = nil
+				} else if w.sharePtr.errVal == nil {
+					w.sharePtr.errVal = w.sel.rows.Err()
+					w.sel.args = nil
+					w.sel.rows = nil
+				}
+			}
+		}
+	}
+	return false
+}
+
+// CloseRows closes the result set generated with a call to Query() or a
+// similar method, releasing the database connection that it holds. Remaining
+// rows are discarded and subsequent calls to Next() return false. It is not
+// needed after Next() has returned false, but it may be called at any time.
+func (w *WrapType) CloseRows() {
+	if w.sel.rows != nil {
+		w.sel.rows.Close()
+	}
+	w.sel.rows = nil
+	w.sel.args = nil
+	w.sel.ctx = nil
 }
 
 // ClearError unsets the current error value.